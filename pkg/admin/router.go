@@ -0,0 +1,87 @@
+package admin
+
+import (
+	baseConfig "github.com/exgamer/gosdk-core/pkg/config"
+	"github.com/exgamer/gosdk-http-core/pkg/health"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"time"
+)
+
+const (
+	MetricsPath = "/metrics"
+	PprofPath   = "/debug/pprof"
+	RuntimePath = "/runtime"
+)
+
+// InitRouter Роутер служебного (admin) сервера: метрики, pprof, health checks и информация о рантайме
+func InitRouter(baseConfig *baseConfig.BaseConfig, healthRegistry *health.Registry, metricsHandler http.Handler) *gin.Engine {
+	router := gin.New()
+	router.Use(gin.Recovery())
+
+	if metricsHandler != nil {
+		router.GET(MetricsPath, gin.WrapH(metricsHandler))
+	}
+
+	if healthRegistry != nil {
+		health.RegisterRoutes(router, healthRegistry)
+	}
+
+	router.GET(RuntimePath, RuntimeHandler(baseConfig))
+
+	registerPprof(router)
+
+	return router
+}
+
+func registerPprof(router *gin.Engine) {
+	group := router.Group(PprofPath)
+
+	group.GET("/", gin.WrapF(pprof.Index))
+	group.GET("/cmdline", gin.WrapF(pprof.Cmdline))
+	group.GET("/profile", gin.WrapF(pprof.Profile))
+	group.GET("/symbol", gin.WrapF(pprof.Symbol))
+	group.POST("/symbol", gin.WrapF(pprof.Symbol))
+	group.GET("/trace", gin.WrapF(pprof.Trace))
+
+	for _, name := range []string{"allocs", "block", "goroutine", "heap", "mutex", "threadcreate"} {
+		group.GET("/"+name, gin.WrapH(pprof.Handler(name)))
+	}
+}
+
+// RuntimeHandler Информация о приложении и рантайме Go
+func RuntimeHandler(baseConfig *baseConfig.BaseConfig) gin.HandlerFunc {
+	startedAt := time.Now()
+
+	return func(c *gin.Context) {
+		var mem runtime.MemStats
+		runtime.ReadMemStats(&mem)
+
+		info := gin.H{
+			"go_version":    runtime.Version(),
+			"num_cpu":       runtime.NumCPU(),
+			"gomaxprocs":    runtime.GOMAXPROCS(0),
+			"num_goroutine": runtime.NumGoroutine(),
+			"started_at":    startedAt.Format(time.RFC3339),
+			"uptime":        time.Since(startedAt).Round(time.Second).String(),
+			"memory": gin.H{
+				"alloc":        mem.Alloc,
+				"sys":          mem.Sys,
+				"heap_inuse":   mem.HeapInuse,
+				"heap_objects": mem.HeapObjects,
+				"num_gc":       mem.NumGC,
+			},
+		}
+
+		if baseConfig != nil {
+			info["service"] = baseConfig.Name
+			info["version"] = baseConfig.Version
+			info["env"] = baseConfig.AppEnv
+		}
+
+		c.Header("Cache-Control", "no-store")
+		c.JSON(http.StatusOK, info)
+	}
+}
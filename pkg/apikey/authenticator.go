@@ -0,0 +1,63 @@
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+)
+
+// DefaultHeader заголовок с API ключом по умолчанию
+const DefaultHeader = "X-Api-Key"
+
+var (
+	ErrKeyMissing = errors.New("api key is missing")
+	ErrKeyInvalid = errors.New("api key is invalid")
+)
+
+// Authenticator проверяет API ключи клиентов
+type Authenticator struct {
+	Provider Provider
+	// Header заголовок с ключом, пустой - DefaultHeader
+	Header string
+	// QueryParam query параметр с ключом (если ключ не пришел в заголовке), пустой - не используется
+	QueryParam string
+}
+
+// Authenticate клиент, которому принадлежит ключ.
+// Хеш ключа сравнивается со всеми клиентами за постоянное время, чтобы по времени ответа нельзя было подобрать ключ
+func (a *Authenticator) Authenticate(ctx context.Context, key string) (*Client, error) {
+	if key == "" {
+		return nil, ErrKeyMissing
+	}
+
+	clients, err := a.Provider.Clients(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.Sum256([]byte(key))
+	var found *Client
+
+	for i := range clients {
+		if subtle.ConstantTimeCompare(hash[:], clients[i].Hash[:]) == 1 && found == nil {
+			found = &clients[i]
+		}
+	}
+
+	if found == nil {
+		return nil, ErrKeyInvalid
+	}
+
+	return found, nil
+}
+
+// GetHeader заголовок с ключом
+func (a *Authenticator) GetHeader() string {
+	if a.Header == "" {
+		return DefaultHeader
+	}
+
+	return a.Header
+}
@@ -0,0 +1,94 @@
+package apikey
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type failingProvider struct{}
+
+func (failingProvider) Clients(context.Context) ([]Client, error) {
+	return nil, errors.New("secrets are unavailable")
+}
+
+func TestAuthenticatorAuthenticate(t *testing.T) {
+	clients, err := ParseClients("billing:" + HashKey("billing-key") + ", crm:" + HashKey("crm-key"))
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		key      string
+		wantId   string
+		wantErr  error
+		provider Provider
+	}{
+		{name: "first client", key: "billing-key", wantId: "billing"},
+		{name: "second client", key: "crm-key", wantId: "crm"},
+		{name: "missing key", key: "", wantErr: ErrKeyMissing},
+		{name: "invalid key", key: "billing-key2", wantErr: ErrKeyInvalid},
+		{name: "key prefix", key: "billing", wantErr: ErrKeyInvalid},
+		{name: "no clients", key: "billing-key", wantErr: ErrKeyInvalid, provider: StaticProvider{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := &Authenticator{Provider: clients}
+
+			if tt.provider != nil {
+				authenticator.Provider = tt.provider
+			}
+
+			client, err := authenticator.Authenticate(context.Background(), tt.key)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && client.Id != tt.wantId {
+				t.Fatalf("Authenticate() client = %s, want %s", client.Id, tt.wantId)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorProviderError(t *testing.T) {
+	authenticator := &Authenticator{Provider: failingProvider{}}
+
+	if _, err := authenticator.Authenticate(context.Background(), "key"); err == nil || errors.Is(err, ErrKeyInvalid) {
+		t.Fatalf("Authenticate() error = %v, want provider error", err)
+	}
+}
+
+func TestParseClients(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    int
+		wantErr bool
+	}{
+		{name: "empty", value: "", want: 0},
+		{name: "trailing comma", value: "a:" + HashKey("a") + ",", want: 1},
+		{name: "without id", value: ":" + HashKey("a"), wantErr: true},
+		{name: "without hash", value: "a", wantErr: true},
+		{name: "short hash", value: "a:abcd", wantErr: true},
+		{name: "not hex", value: "a:" + HashKey("a")[:62] + "zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clients, err := ParseClients(tt.value)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseClients() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !tt.wantErr && len(clients) != tt.want {
+				t.Fatalf("ParseClients() = %d clients, want %d", len(clients), tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package apikey
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// Client клиент (интеграция) с API ключом. Хранится только sha256 хеш ключа
+type Client struct {
+	Id   string
+	Hash [sha256.Size]byte
+}
+
+// Provider источник клиентов API ключей (конфиг, БД, секреты)
+type Provider interface {
+	Clients(ctx context.Context) ([]Client, error)
+}
+
+// StaticProvider неизменяемый набор клиентов
+type StaticProvider []Client
+
+func (p StaticProvider) Clients(_ context.Context) ([]Client, error) {
+	return p, nil
+}
+
+// HashKey sha256 хеш ключа в hex - в таком виде ключи задаются в API_KEYS
+func HashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])
+}
+
+// ParseClients клиенты из строки вида "client1:<sha256 hex>,client2:<sha256 hex>"
+func ParseClients(value string) (StaticProvider, error) {
+	clients := make(StaticProvider, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+
+		if item == "" {
+			continue
+		}
+
+		id, hash, found := strings.Cut(item, ":")
+
+		if !found || id == "" {
+			return nil, fmt.Errorf("apikey: invalid client %q, expected <client id>:<sha256 hex>", item)
+		}
+
+		client, err := NewClient(id, hash)
+
+		if err != nil {
+			return nil, err
+		}
+
+		clients = append(clients, client)
+	}
+
+	return clients, nil
+}
+
+// NewClient клиент по id и sha256 хешу ключа в hex
+func NewClient(id string, hashHex string) (Client, error) {
+	decoded, err := hex.DecodeString(strings.TrimSpace(hashHex))
+
+	if err != nil || len(decoded) != sha256.Size {
+		return Client{}, fmt.Errorf("apikey: hash of client %s must be sha256 hex", id)
+	}
+
+	client := Client{Id: id}
+	copy(client.Hash[:], decoded)
+
+	return client, nil
+}
@@ -7,22 +7,45 @@ import (
 	baseConfig "github.com/exgamer/gosdk-core/pkg/config"
 	"github.com/exgamer/gosdk-core/pkg/di"
 	"github.com/exgamer/gosdk-core/pkg/logger"
+	"github.com/exgamer/gosdk-http-core/pkg/admin"
+	"github.com/exgamer/gosdk-http-core/pkg/apikey"
+	"github.com/exgamer/gosdk-http-core/pkg/certificates"
 	"github.com/exgamer/gosdk-http-core/pkg/config"
 	ginHelper "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"github.com/exgamer/gosdk-http-core/pkg/health"
+	"github.com/exgamer/gosdk-http-core/pkg/jwt"
 	"github.com/exgamer/gosdk-http-core/pkg/metrics"
+	"github.com/exgamer/gosdk-http-core/pkg/middleware"
+	"github.com/exgamer/gosdk-http-core/pkg/tracing"
 	"github.com/getsentry/sentry-go"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	"log"
 	"net/http"
+	"strings"
 	"time"
 )
 
 const HttpKernelName = "http"
 
 type HttpKernel struct {
-	HttpConfig *config.HttpConfig
-	Router     *gin.Engine
-	Server     *http.Server
+	HttpConfig      *config.HttpConfig
+	Router          *gin.Engine
+	Server          *http.Server
+	CertReloader    *certificates.Reloader
+	Health          *health.Registry
+	InFlight        *InFlightTracker
+	AdminRouter     *gin.Engine
+	AdminServer     *http.Server
+	MetricsRegistry *prometheus.Registry
+	// TraceExporter экспортер спанов. Если задан до Init, используется вместо OTLP
+	// (например, tracetest.NewInMemoryExporter() в тестах) и трейсинг включается независимо от TRACING_ENABLED
+	TraceExporter  sdktrace.SpanExporter
+	TracerProvider *sdktrace.TracerProvider
+
+	ctx context.Context
 }
 
 func (m *HttpKernel) Name() string {
@@ -30,6 +53,8 @@ func (m *HttpKernel) Name() string {
 }
 
 func (m *HttpKernel) Init(a *app.App) error {
+	m.ctx = a.GetContext()
+
 	{
 		httpConfig := &config.HttpConfig{}
 		err := baseConfig.InitConfig(httpConfig)
@@ -38,6 +63,10 @@ func (m *HttpKernel) Init(a *app.App) error {
 			return err
 		}
 
+		if err := httpConfig.Validate(); err != nil {
+			return err
+		}
+
 		m.HttpConfig = httpConfig
 
 		logger.Dump(a.GetContext(), httpConfig)
@@ -57,41 +86,134 @@ func (m *HttpKernel) Init(a *app.App) error {
 
 	m.Router = ginHelper.InitRouter(a.BaseConfig, m.HttpConfig)
 
+	appConfig, err := di.GetBaseConfig(a.Container)
+
+	if err != nil {
+		return err
+	}
+
+	// у kernel свой registry прометея, в нем же регистрируют свои метрики сервисы
+	m.MetricsRegistry = metrics.NewRegistry()
+	di.Register(a.Container, m.MetricsRegistry)
+
+	metricsCollector := metrics.NewCollector(appConfig.Name, m.MetricsRegistry)
+	metricsCollector.SetExemplarsEnabled(m.HttpConfig.MetricsExemplars)
+	metricsCollector.SetDefaultRegistryIncluded(m.HttpConfig.MetricsIncludeDefaultRegistry)
+
+	di.Register(a.Container, metricsCollector)
+
+	m.Health = health.NewRegistry()
+	di.Register(a.Container, m.Health)
+
+	// проверка JWT подключается сервисом на нужные роуты через middleware.JwtMiddleware
+	if m.HttpConfig.IsJwtEnabled() {
+		jwtVerifier, err := jwt.NewVerifierFromConfig(m.HttpConfig)
+
+		if err != nil {
+			return err
+		}
+
+		di.Register(a.Container, jwtVerifier)
+	}
+
+	// проверка API ключей подключается сервисом на нужные роуты через middleware.ApiKeyMiddleware
+	if m.HttpConfig.IsApiKeyEnabled() {
+		apiKeyClients, err := apikey.ParseClients(m.HttpConfig.ApiKeys)
+
+		if err != nil {
+			return err
+		}
+
+		di.Register(a.Container, &apikey.Authenticator{
+			Provider:   apiKeyClients,
+			Header:     m.HttpConfig.ApiKeyHeader,
+			QueryParam: m.HttpConfig.ApiKeyQueryParam,
+		})
+	}
+
+	if m.HttpConfig.IsAdminServerEnabled() {
+		// служебные роуты живут только на отдельном сервере
+		m.AdminRouter = admin.InitRouter(a.BaseConfig, m.Health, metricsCollector.Handler())
+		m.AdminServer = &http.Server{
+			Addr:              m.HttpConfig.AdminServerAddress,
+			Handler:           m.AdminRouter,
+			ReadHeaderTimeout: m.HttpConfig.GetReadHeaderTimeout(),
+			IdleTimeout:       m.HttpConfig.GetIdleTimeout(),
+			// WriteTimeout не задаем: pprof профилирование может длиться дольше обычного запроса
+		}
+	} else {
+		// служебные роуты регистрируем до остальных middleware, чтобы пробы и сбор метрик не попадали в логи и метрики
+		m.Router.GET(admin.MetricsPath, gin.WrapH(metricsCollector.Handler()))
+		health.RegisterRoutes(m.Router, m.Health)
+	}
+
 	m.Router.Use(func(c *gin.Context) {
-		// подменяем context у запроса
-		c.Request = c.Request.WithContext(a.GetContext())
+		// дополняем context запроса значениями приложения, сохраняя его отмену и дедлайн
+		c.Request = c.Request.WithContext(NewRequestContext(c.Request.Context(), a.GetContext()))
 
 		c.Next()
 	})
 
-	di.Register(a.Container, m.Router)
+	if m.HttpConfig.TracingEnabled || m.TraceExporter != nil {
+		if err := m.initTracing(a); err != nil {
+			return err
+		}
+	}
 
-	appConfig, err := di.GetBaseConfig(a.Container)
+	// отслеживаем запросы в обработке для graceful shutdown
+	m.InFlight = NewInFlightTracker(metricsCollector)
+	m.Router.Use(m.InFlight.Middleware())
 
-	if err != nil {
-		return err
-	}
+	// единый набор HTTP метрик для всех роутов
+	m.Router.Use(middleware.MetricsMiddleware(a))
 
-	metricsCollector := metrics.NewCollector(appConfig.Name)
+	// лимит размера тела запроса, роуты могут задать свой через middleware.BodyLimitMiddleware
+	if maxRequestBodySize := m.HttpConfig.GetMaxRequestBodySize(); maxRequestBodySize > 0 {
+		m.Router.Use(middleware.BodyLimitMiddleware(maxRequestBodySize))
+	}
 
-	di.Register(a.Container, metricsCollector)
+	di.Register(a.Container, m.Router)
 
 	m.Server = &http.Server{
-		Addr:    m.HttpConfig.ServerAddress,
-		Handler: m.Router, // <-- gin как handler
-		//@TODO возможно вынести в настройки
-		ReadTimeout:       15 * time.Second,
-		ReadHeaderTimeout: 10 * time.Second,
-		WriteTimeout:      30 * time.Second,
-		IdleTimeout:       60 * time.Second,
+		Addr:              m.HttpConfig.ServerAddress,
+		Handler:           m.Router, // <-- gin как handler
+		ReadTimeout:       m.HttpConfig.GetReadTimeout(),
+		ReadHeaderTimeout: m.HttpConfig.GetReadHeaderTimeout(),
+		WriteTimeout:      m.HttpConfig.GetWriteTimeout(),
+		IdleTimeout:       m.HttpConfig.GetIdleTimeout(),
+		MaxHeaderBytes:    m.HttpConfig.GetMaxHeaderBytes(),
+	}
+
+	// TLS / mTLS
+	if m.HttpConfig.IsTlsEnabled() {
+		clientAuth, err := certificates.ParseClientAuthType(m.HttpConfig.TlsClientAuth)
+
+		if err != nil {
+			return err
+		}
+
+		reloader, err := certificates.NewReloader(m.HttpConfig.TlsCertFile, m.HttpConfig.TlsKeyFile, m.HttpConfig.TlsClientCaFile, clientAuth)
+
+		if err != nil {
+			return err
+		}
+
+		m.CertReloader = reloader
+		m.Server.TLSConfig = reloader.TlsConfig()
 	}
 
 	return nil
 }
 
 func (m *HttpKernel) Start(a *app.App) error {
+	if m.CertReloader != nil {
+		if err := m.CertReloader.Watch(a.GetContext()); err != nil {
+			return err
+		}
+	}
+
 	go func() {
-		if err := m.Server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		if err := m.listenAndServe(); err != nil && err != http.ErrServerClosed {
 			if a != nil {
 				a.Fail(fmt.Errorf("http server: %w", err))
 
@@ -101,6 +223,19 @@ func (m *HttpKernel) Start(a *app.App) error {
 		}
 	}()
 
+	if m.AdminServer != nil {
+		go func() {
+			if err := m.AdminServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				if a != nil {
+					a.Fail(fmt.Errorf("http admin server: %w", err))
+
+					return
+				}
+				log.Printf("http admin server error: %v", err)
+			}
+		}()
+	}
+
 	return nil
 }
 
@@ -109,9 +244,128 @@ func (m *HttpKernel) Stop(ctx context.Context) error {
 		return nil
 	}
 
+	// сначала проваливаем readiness, чтобы балансировщик перестал слать трафик
+	if m.Health != nil {
+		m.Health.SetShuttingDown()
+	}
+
+	logger.Info(m.ctx, fmt.Sprintf("http server shutdown started, in-flight requests: %d", m.inFlightCount()))
+
+	// даем балансировщику время вывести инстанс, пока сервер еще принимает запросы
+	if delay := m.HttpConfig.GetShutdownDelay(); delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+		}
+	}
+
 	// если ctx без дедлайна, App уже даёт timeout — ок
-	err := m.Server.Shutdown(ctx)
-	_ = sentry.Flush(2 * time.Second)
+	graceCtx := ctx
+
+	if grace := m.HttpConfig.GetShutdownGracePeriod(); grace > 0 {
+		var cancel context.CancelFunc
+		graceCtx, cancel = context.WithTimeout(ctx, grace)
+		defer cancel()
+	}
+
+	err := m.Server.Shutdown(graceCtx)
+
+	// запросы не уложились в grace period — отменяем их context и ждем до конца таймаута остановки
+	if err != nil && m.InFlight != nil {
+		m.logCancelledRequests(m.InFlight.CancelAll())
+
+		if waitErr := m.InFlight.Wait(ctx); waitErr == nil {
+			err = nil
+		} else {
+			_ = m.Server.Close()
+		}
+	}
+
+	logger.Info(m.ctx, fmt.Sprintf("http server stopped, in-flight requests: %d", m.inFlightCount()))
+
+	_ = sentry.Flush(m.HttpConfig.GetSentryFlushTimeout())
+
+	if m.CertReloader != nil {
+		_ = m.CertReloader.Close()
+	}
+
+	// отправляем накопленные спаны
+	if m.TracerProvider != nil {
+		if tracingErr := m.TracerProvider.Shutdown(ctx); tracingErr != nil {
+			logger.Warning(m.ctx, "tracer provider shutdown: "+tracingErr.Error())
+		}
+	}
+
+	// служебный сервер останавливаем последним, чтобы метрики и пробы были доступны во время drain
+	if m.AdminServer != nil {
+		if adminErr := m.AdminServer.Shutdown(ctx); adminErr != nil && err == nil {
+			err = adminErr
+		}
+	}
 
 	return err
 }
+
+// initTracing инициализирует OpenTelemetry трейсинг и подключает TracingMiddleware
+func (m *HttpKernel) initTracing(a *app.App) error {
+	if m.TraceExporter == nil {
+		exporter, err := tracing.NewOtlpExporter(a.GetContext(), m.HttpConfig.TracingOtlpEndpoint)
+
+		if err != nil {
+			return err
+		}
+
+		m.TraceExporter = exporter
+	}
+
+	tracerProvider, err := tracing.NewTracerProvider(a.BaseConfig, m.TraceExporter, m.HttpConfig.GetTracingSampleRatio())
+
+	if err != nil {
+		return err
+	}
+
+	m.TracerProvider = tracerProvider
+	propagator := tracing.NewPropagator()
+
+	otel.SetTracerProvider(tracerProvider)
+	otel.SetTextMapPropagator(propagator)
+	di.Register(a.Container, m.TracerProvider)
+
+	m.Router.Use(middleware.TracingMiddleware(tracerProvider.Tracer(tracing.TracerName), propagator))
+
+	return nil
+}
+
+func (m *HttpKernel) inFlightCount() int64 {
+	if m.InFlight == nil {
+		return 0
+	}
+
+	return m.InFlight.Count()
+}
+
+// logCancelledRequests пишет итоговый лог запросов, оборванных при остановке
+func (m *HttpKernel) logCancelledRequests(requests []InFlightRequest) {
+	if len(requests) == 0 {
+		return
+	}
+
+	messageBuilder := strings.Builder{}
+	messageBuilder.WriteString(fmt.Sprintf("http server shutdown: %d requests cut off after grace period:", len(requests)))
+
+	for _, r := range requests {
+		messageBuilder.WriteString(fmt.Sprintf(" [%s,%s,%s,%s]", r.RequestId, r.Method, r.Url, time.Since(r.StartedAt).Round(time.Millisecond)))
+	}
+
+	logger.Warning(m.ctx, messageBuilder.String())
+}
+
+// listenAndServe поднимает сервер с TLS, если он настроен
+func (m *HttpKernel) listenAndServe() error {
+	if m.Server.TLSConfig != nil {
+		// сертификаты отдает TLSConfig, поэтому пути к файлам не передаем
+		return m.Server.ListenAndServeTLS("", "")
+	}
+
+	return m.Server.ListenAndServe()
+}
@@ -0,0 +1,131 @@
+package app
+
+import (
+	"context"
+	"github.com/exgamer/gosdk-http-core/pkg/constants"
+	ginHelper "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"github.com/exgamer/gosdk-http-core/pkg/metrics"
+	"github.com/gin-gonic/gin"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// InFlightRequest данные запроса, который сейчас в обработке
+type InFlightRequest struct {
+	Method    string
+	Url       string
+	RequestId string
+	StartedAt time.Time
+
+	cancel context.CancelFunc
+}
+
+func NewInFlightTracker(collector *metrics.Collector) *InFlightTracker {
+	return &InFlightTracker{
+		collector: collector,
+		requests:  make(map[uint64]*InFlightRequest),
+	}
+}
+
+// InFlightTracker отслеживает запросы в обработке, чтобы корректно дождаться их при остановке
+// и отменить контексты тех, что не уложились в grace period
+type InFlightTracker struct {
+	collector *metrics.Collector
+
+	mu       sync.Mutex
+	seq      uint64
+	requests map[uint64]*InFlightRequest
+	count    atomic.Int64
+}
+
+// Middleware регистрирует запрос на время обработки и подменяет его context на отменяемый
+func (t *InFlightTracker) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := ginHelper.GetRoutePath(c)
+
+		ctx, cancel := context.WithCancel(c.Request.Context())
+		defer cancel()
+
+		c.Request = c.Request.WithContext(ctx)
+
+		id := t.add(&InFlightRequest{
+			Method:    c.Request.Method,
+			Url:       c.Request.URL.Path,
+			RequestId: c.GetHeader(constants.RequestIdHeaderName),
+			StartedAt: time.Now(),
+			cancel:    cancel,
+		})
+
+		if t.collector != nil {
+			t.collector.IncInFlight(c.Request.Method, path)
+		}
+
+		defer func() {
+			t.remove(id)
+
+			if t.collector != nil {
+				t.collector.DecInFlight(c.Request.Method, path)
+			}
+		}()
+
+		c.Next()
+	}
+}
+
+// Count количество запросов в обработке
+func (t *InFlightTracker) Count() int64 {
+	return t.count.Load()
+}
+
+// CancelAll отменяет контексты всех запросов в обработке и возвращает их список
+func (t *InFlightTracker) CancelAll() []InFlightRequest {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	cancelled := make([]InFlightRequest, 0, len(t.requests))
+
+	for _, r := range t.requests {
+		r.cancel()
+		cancelled = append(cancelled, *r)
+	}
+
+	return cancelled
+}
+
+// Wait ждет завершения всех запросов или отмены ctx
+func (t *InFlightTracker) Wait(ctx context.Context) error {
+	ticker := time.NewTicker(50 * time.Millisecond)
+	defer ticker.Stop()
+
+	for t.Count() > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+
+	return nil
+}
+
+func (t *InFlightTracker) add(r *InFlightRequest) uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.seq++
+	t.requests[t.seq] = r
+	t.count.Add(1)
+
+	return t.seq
+}
+
+func (t *InFlightTracker) remove(id uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.requests[id]; ok {
+		delete(t.requests, id)
+		t.count.Add(-1)
+	}
+}
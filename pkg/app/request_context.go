@@ -0,0 +1,31 @@
+package app
+
+import "context"
+
+// NewRequestContext возвращает context запроса, дополненный значениями context приложения (AppInfo, логгер и т.д.).
+// Отмена и дедлайн берутся только из context запроса, поэтому при разрыве соединения клиентом
+// запросы к БД и внешним сервисам прерываются, а остановка приложения не обрывает запросы в обработке
+func NewRequestContext(requestCtx context.Context, appCtx context.Context) context.Context {
+	if appCtx == nil {
+		return requestCtx
+	}
+
+	return &requestContext{
+		Context: requestCtx,
+		appCtx:  appCtx,
+	}
+}
+
+type requestContext struct {
+	context.Context
+	appCtx context.Context
+}
+
+// Value значения запроса в приоритете, если не нашли - ищем в context приложения
+func (c *requestContext) Value(key any) any {
+	if v := c.Context.Value(key); v != nil {
+		return v
+	}
+
+	return c.appCtx.Value(key)
+}
@@ -0,0 +1,149 @@
+package authz
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	DefaultPermissionsClaim = "permissions"
+	DefaultRolesClaim       = "roles"
+	DefaultCompanyIdsClaim  = "company_ids"
+	// ScopeClaim OAuth2 scope через пробел, используется, если в токене нет claim с permissions
+	ScopeClaim = "scope"
+)
+
+// ClaimsPolicy права из claims JWT: permissions, roles и company_ids (имена claims настраиваются).
+// Без claims в context (запрос без JwtMiddleware) доступ запрещен
+type ClaimsPolicy struct {
+	PermissionsClaim string
+	RolesClaim       string
+	CompanyIdsClaim  string
+}
+
+func (p *ClaimsPolicy) HasPermissions(_ context.Context, subject *Subject, permissions []string) (bool, error) {
+	claims, err := subjectClaims(subject)
+
+	if err != nil || claims == nil {
+		return false, err
+	}
+
+	granted := stringsClaim(claims[orDefault(p.PermissionsClaim, DefaultPermissionsClaim)])
+
+	if granted == nil {
+		granted = stringsClaim(claims[ScopeClaim])
+	}
+
+	for _, permission := range permissions {
+		if !slices.Contains(granted, permission) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (p *ClaimsPolicy) HasRoles(_ context.Context, subject *Subject, roles []string) (bool, error) {
+	claims, err := subjectClaims(subject)
+
+	if err != nil || claims == nil {
+		return false, err
+	}
+
+	granted := stringsClaim(claims[orDefault(p.RolesClaim, DefaultRolesClaim)])
+
+	return slices.ContainsFunc(roles, func(role string) bool {
+		return slices.Contains(granted, role)
+	}), nil
+}
+
+func (p *ClaimsPolicy) HasCompanyAccess(_ context.Context, subject *Subject, companyIds []int) (bool, error) {
+	claims, err := subjectClaims(subject)
+
+	if err != nil || claims == nil {
+		return false, err
+	}
+
+	granted := intsClaim(claims[orDefault(p.CompanyIdsClaim, DefaultCompanyIdsClaim)])
+
+	for _, companyId := range companyIds {
+		if !slices.Contains(granted, companyId) {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func subjectClaims(subject *Subject) (map[string]any, error) {
+	if subject == nil || subject.Claims == nil {
+		return nil, nil
+	}
+
+	claims := make(map[string]any)
+
+	if err := subject.Claims.Unmarshal(&claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+// stringsClaim массив строк или строка через пробел
+func stringsClaim(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		items := make([]string, 0, len(v))
+
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				items = append(items, s)
+			}
+		}
+
+		return items
+	default:
+		return nil
+	}
+}
+
+// intsClaim массив чисел (в том числе строками)
+func intsClaim(value any) []int {
+	list, ok := value.([]any)
+
+	if !ok {
+		return nil
+	}
+
+	items := make([]int, 0, len(list))
+
+	for _, item := range list {
+		switch v := item.(type) {
+		case float64:
+			items = append(items, int(v))
+		case json.Number:
+			if i, err := v.Int64(); err == nil {
+				items = append(items, int(i))
+			}
+		case string:
+			if i, err := strconv.Atoi(v); err == nil {
+				items = append(items, i)
+			}
+		}
+	}
+
+	return items
+}
+
+func orDefault(value string, def string) string {
+	if value == "" {
+		return def
+	}
+
+	return value
+}
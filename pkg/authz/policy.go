@@ -0,0 +1,41 @@
+package authz
+
+import (
+	"context"
+	"github.com/exgamer/gosdk-http-core/pkg/config"
+	"github.com/exgamer/gosdk-http-core/pkg/jwt"
+	"sync/atomic"
+)
+
+// Subject тот, кто выполняет запрос: claims токена (если был JwtMiddleware) и данные запроса
+type Subject struct {
+	Claims   *jwt.Claims
+	HttpInfo *config.HttpInfo
+}
+
+// Policy источник прав для guard'ов роутов. Реализуется командой под свой RBAC/ABAC
+// (claims токена, сервис прав, БД). Ошибка означает, что права проверить не удалось, и отдается как 500
+type Policy interface {
+	// HasPermissions есть ли у субъекта все permissions
+	HasPermissions(ctx context.Context, subject *Subject, permissions []string) (bool, error)
+	// HasRoles есть ли у субъекта хотя бы одна из ролей
+	HasRoles(ctx context.Context, subject *Subject, roles []string) (bool, error)
+	// HasCompanyAccess есть ли у субъекта доступ ко всем компаниям
+	HasCompanyAccess(ctx context.Context, subject *Subject, companyIds []int) (bool, error)
+}
+
+var defaultPolicy atomic.Value
+
+func init() {
+	SetPolicy(&ClaimsPolicy{})
+}
+
+// SetPolicy задает политику, которой пользуются guard'ы без явно переданной политики
+func SetPolicy(policy Policy) {
+	defaultPolicy.Store(&policy)
+}
+
+// GetPolicy политика по умолчанию (ClaimsPolicy, если не задана SetPolicy)
+func GetPolicy() Policy {
+	return *defaultPolicy.Load().(*Policy)
+}
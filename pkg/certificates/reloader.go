@@ -0,0 +1,231 @@
+package certificates
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/exgamer/gosdk-core/pkg/logger"
+	"github.com/fsnotify/fsnotify"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+// Режимы проверки клиентского сертификата (TLS_CLIENT_AUTH)
+const (
+	ClientAuthNone             = "none"
+	ClientAuthRequest          = "request"
+	ClientAuthRequire          = "require"
+	ClientAuthVerifyIfGiven    = "verify_if_given"
+	ClientAuthRequireAndVerify = "require_and_verify"
+)
+
+// ParseClientAuthType возвращает tls.ClientAuthType по названию режима
+func ParseClientAuthType(mode string) (tls.ClientAuthType, error) {
+	switch strings.ToLower(strings.TrimSpace(mode)) {
+	case "", ClientAuthNone:
+		return tls.NoClientCert, nil
+	case ClientAuthRequest:
+		return tls.RequestClientCert, nil
+	case ClientAuthRequire:
+		return tls.RequireAnyClientCert, nil
+	case ClientAuthVerifyIfGiven:
+		return tls.VerifyClientCertIfGiven, nil
+	case ClientAuthRequireAndVerify:
+		return tls.RequireAndVerifyClientCert, nil
+	default:
+		return tls.NoClientCert, fmt.Errorf("unknown tls client auth mode: %s", mode)
+	}
+}
+
+// NewReloader создает Reloader и сразу загружает сертификаты с диска
+func NewReloader(certFile string, keyFile string, clientCaFile string, clientAuth tls.ClientAuthType) (*Reloader, error) {
+	if certFile == "" || keyFile == "" {
+		return nil, errors.New("tls cert file and key file are required")
+	}
+
+	if clientAuth >= tls.VerifyClientCertIfGiven && clientCaFile == "" {
+		return nil, errors.New("tls client ca file is required to verify client certificates")
+	}
+
+	r := &Reloader{
+		certFile:     certFile,
+		keyFile:      keyFile,
+		clientCaFile: clientCaFile,
+		clientAuth:   clientAuth,
+	}
+
+	if err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reloader держит актуальные сертификат сервера и пул CA клиентов,
+// перечитывая их с диска при изменении файлов
+type Reloader struct {
+	certFile     string
+	keyFile      string
+	clientCaFile string
+	clientAuth   tls.ClientAuthType
+
+	mu        sync.RWMutex
+	cert      *tls.Certificate
+	clientCAs *x509.CertPool
+
+	watcher *fsnotify.Watcher
+}
+
+// Reload перечитывает сертификаты с диска. При ошибке остаются предыдущие
+func (r *Reloader) Reload() error {
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+
+	if err != nil {
+		return fmt.Errorf("load tls key pair: %w", err)
+	}
+
+	var clientCAs *x509.CertPool
+
+	if r.clientCaFile != "" {
+		pem, err := os.ReadFile(r.clientCaFile)
+
+		if err != nil {
+			return fmt.Errorf("read tls client ca file: %w", err)
+		}
+
+		clientCAs = x509.NewCertPool()
+
+		if !clientCAs.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in tls client ca file: %s", r.clientCaFile)
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = &cert
+	r.clientCAs = clientCAs
+	r.mu.Unlock()
+
+	return nil
+}
+
+// TlsConfig возвращает tls.Config для http.Server, который на каждое соединение берет актуальные сертификаты
+func (r *Reloader) TlsConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: r.getConfigForClient,
+	}
+}
+
+func (r *Reloader) getConfigForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		ClientAuth:   r.clientAuth,
+		ClientCAs:    r.clientCAs,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}, nil
+}
+
+// Watch следит за директориями сертификатов и перечитывает их при изменении.
+// Следим именно за директориями, чтобы ловить атомарную подмену файлов (например, secrets в k8s)
+func (r *Reloader) Watch(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+
+	if err != nil {
+		return err
+	}
+
+	dirs := make(map[string]struct{})
+	watched := make(map[string]struct{})
+
+	for _, file := range []string{r.certFile, r.keyFile, r.clientCaFile} {
+		if file == "" {
+			continue
+		}
+
+		dirs[filepath.Dir(file)] = struct{}{}
+		watched[filepath.Clean(file)] = struct{}{}
+	}
+
+	for dir := range dirs {
+		if err := watcher.Add(dir); err != nil {
+			_ = watcher.Close()
+
+			return fmt.Errorf("watch tls dir %s: %w", dir, err)
+		}
+	}
+
+	r.mu.Lock()
+	r.watcher = watcher
+	r.mu.Unlock()
+
+	go func() {
+		for {
+			select {
+			case <-ctx.Done():
+				_ = watcher.Close()
+
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+
+				if !r.isRelevant(event, watched) {
+					continue
+				}
+
+				if err := r.Reload(); err != nil {
+					logger.Error(ctx, "tls certificates reload failed: "+err.Error())
+
+					continue
+				}
+
+				logger.Info(ctx, "tls certificates reloaded")
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+
+				logger.Error(ctx, "tls certificates watcher error: "+err.Error())
+			}
+		}
+	}()
+
+	return nil
+}
+
+// Close останавливает слежение за файлами
+func (r *Reloader) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.watcher == nil {
+		return nil
+	}
+
+	err := r.watcher.Close()
+	r.watcher = nil
+
+	return err
+}
+
+func (r *Reloader) isRelevant(event fsnotify.Event, watched map[string]struct{}) bool {
+	if event.Has(fsnotify.Chmod) {
+		return false
+	}
+
+	if _, ok := watched[filepath.Clean(event.Name)]; ok {
+		return true
+	}
+
+	// k8s монтирует секреты через симлинк ..data, который подменяется целиком
+	return strings.HasPrefix(filepath.Base(event.Name), "..")
+}
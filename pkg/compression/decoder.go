@@ -0,0 +1,122 @@
+package compression
+
+import (
+	"bufio"
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zlib"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"sync"
+)
+
+// maxZstdWindow максимальное окно zstd, ограничивает память decoder
+const maxZstdWindow = 8 << 20
+
+var (
+	gzipDecoders   sync.Pool
+	brotliDecoders sync.Pool
+	zstdDecoders   = sync.Pool{New: func() any {
+		decoder, _ := zstd.NewReader(nil, zstd.WithDecoderConcurrency(1), zstd.WithDecoderLowmem(true), zstd.WithDecoderMaxWindow(maxZstdWindow))
+
+		return decoder
+	}}
+)
+
+// NewDecoder распаковывающий reader для Content-Encoding encoding.
+// Close возвращает decoder в пул, исходный r не закрывается
+func NewDecoder(encoding string, r io.Reader) (io.ReadCloser, error) {
+	switch encoding {
+	case Gzip:
+		decoder, _ := gzipDecoders.Get().(*gzip.Reader)
+
+		if decoder == nil {
+			var err error
+
+			if decoder, err = gzip.NewReader(r); err != nil {
+				return nil, err
+			}
+		} else if err := decoder.Reset(r); err != nil {
+			gzipDecoders.Put(decoder)
+
+			return nil, err
+		}
+
+		return newPooledDecoder(decoder, func() { gzipDecoders.Put(decoder) }), nil
+	case Brotli:
+		decoder, _ := brotliDecoders.Get().(*brotli.Reader)
+
+		if decoder == nil {
+			decoder = brotli.NewReader(r)
+		} else if err := decoder.Reset(r); err != nil {
+			return nil, err
+		}
+
+		return newPooledDecoder(decoder, func() { brotliDecoders.Put(decoder) }), nil
+	case Zstd:
+		decoder := zstdDecoders.Get().(*zstd.Decoder)
+
+		if err := decoder.Reset(r); err != nil {
+			zstdDecoders.Put(decoder)
+
+			return nil, err
+		}
+
+		return newPooledDecoder(decoder, func() {
+			// не держим ссылку на тело запроса
+			_ = decoder.Reset(nil)
+			zstdDecoders.Put(decoder)
+		}), nil
+	case Deflate:
+		return newDeflateDecoder(r)
+	default:
+		return nil, fmt.Errorf("compression: unsupported encoding %q", encoding)
+	}
+}
+
+// newDeflateDecoder deflate по RFC 9110 - поток zlib, но часть клиентов отправляет "голый" deflate: различаем по заголовку zlib
+func newDeflateDecoder(r io.Reader) (io.ReadCloser, error) {
+	buffered := bufio.NewReader(r)
+	header, err := buffered.Peek(2)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(buffered)
+	}
+
+	return flate.NewReader(buffered), nil
+}
+
+// pooledDecoder decoder, который при Close возвращается в пул
+type pooledDecoder struct {
+	reader  io.Reader
+	release func()
+	closed  bool
+}
+
+func newPooledDecoder(r io.Reader, release func()) *pooledDecoder {
+	return &pooledDecoder{reader: r, release: release}
+}
+
+func (d *pooledDecoder) Read(p []byte) (int, error) {
+	// decoder после Close может уже распаковывать другой запрос
+	if d.closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	return d.reader.Read(p)
+}
+
+func (d *pooledDecoder) Close() error {
+	if !d.closed {
+		d.closed = true
+		d.release()
+	}
+
+	return nil
+}
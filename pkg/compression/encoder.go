@@ -0,0 +1,63 @@
+package compression
+
+import (
+	"fmt"
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/flate"
+	"github.com/klauspost/compress/gzip"
+	"github.com/klauspost/compress/zstd"
+	"io"
+	"sync"
+)
+
+// Encoder сжимающий writer, который можно переиспользовать через Reset
+type Encoder interface {
+	io.WriteCloser
+	Flush() error
+	Reset(w io.Writer)
+}
+
+var encoderPools = map[string]*sync.Pool{
+	Gzip: {New: func() any {
+		encoder, _ := gzip.NewWriterLevel(io.Discard, gzip.DefaultCompression)
+
+		return encoder
+	}},
+	Deflate: {New: func() any {
+		encoder, _ := flate.NewWriter(io.Discard, flate.DefaultCompression)
+
+		return encoder
+	}},
+	// уровень 4 - разумный компромисс скорости и степени сжатия для динамических ответов
+	Brotli: {New: func() any {
+		return brotli.NewWriterLevel(io.Discard, 4)
+	}},
+	Zstd: {New: func() any {
+		encoder, _ := zstd.NewWriter(io.Discard, zstd.WithEncoderConcurrency(1), zstd.WithLowerEncoderMem(true))
+
+		return encoder
+	}},
+}
+
+// GetEncoder encoder из пула, пишущий в w. После Close возвращается в пул через PutEncoder
+func GetEncoder(encoding string, w io.Writer) (Encoder, error) {
+	pool, ok := encoderPools[encoding]
+
+	if !ok {
+		return nil, fmt.Errorf("compression: unsupported encoding %q", encoding)
+	}
+
+	encoder := pool.Get().(Encoder)
+	encoder.Reset(w)
+
+	return encoder, nil
+}
+
+// PutEncoder возвращает encoder в пул
+func PutEncoder(encoding string, encoder Encoder) {
+	if pool, ok := encoderPools[encoding]; ok {
+		// не держим ссылку на writer запроса
+		encoder.Reset(io.Discard)
+		pool.Put(encoder)
+	}
+}
@@ -0,0 +1,69 @@
+package compression
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Поддерживаемые Content-Encoding
+const (
+	Gzip    = "gzip"
+	Deflate = "deflate"
+	Brotli  = "br"
+	Zstd    = "zstd"
+)
+
+// DefaultEncodings кодировки ответа в порядке предпочтения сервера
+var DefaultEncodings = []string{Zstd, Brotli, Gzip}
+
+// Negotiate выбирает кодировку ответа по Accept-Encoding: с наибольшим q, при равном q - первую из supported.
+// Пустая строка - ответ отдается без сжатия
+func Negotiate(acceptEncoding string, supported []string) string {
+	if acceptEncoding == "" {
+		return ""
+	}
+
+	weights := make(map[string]float64)
+	wildcard := -1.0
+
+	for _, part := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		weight := 1.0
+
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if parsed, err := strconv.ParseFloat(q, 64); err == nil {
+				weight = parsed
+			}
+		}
+
+		if name == "*" {
+			wildcard = weight
+		} else if name != "" {
+			weights[name] = weight
+		}
+	}
+
+	best := ""
+	bestWeight := 0.0
+
+	for _, encoding := range supported {
+		weight, ok := weights[encoding]
+
+		if !ok {
+			weight = wildcard
+		}
+
+		if weight > bestWeight {
+			best, bestWeight = encoding, weight
+		}
+	}
+
+	return best
+}
+
+// IsSupported поддерживается ли кодировка
+func IsSupported(encoding string) bool {
+	return slices.Contains([]string{Gzip, Deflate, Brotli, Zstd}, encoding)
+}
@@ -0,0 +1,32 @@
+package compression
+
+import "testing"
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		acceptEncoding string
+		want           string
+	}{
+		{acceptEncoding: "", want: ""},
+		{acceptEncoding: "gzip", want: Gzip},
+		{acceptEncoding: "gzip, br, zstd", want: Zstd},
+		{acceptEncoding: "gzip, br", want: Brotli},
+		{acceptEncoding: "GZIP", want: Gzip},
+		{acceptEncoding: "br;q=0.5, gzip;q=0.8", want: Gzip},
+		{acceptEncoding: "zstd;q=0, gzip", want: Gzip},
+		{acceptEncoding: "*", want: Zstd},
+		{acceptEncoding: "*;q=0.1, gzip;q=0.5", want: Gzip},
+		{acceptEncoding: "*, zstd;q=0, br;q=0", want: Gzip},
+		{acceptEncoding: "identity", want: ""},
+		{acceptEncoding: "deflate", want: ""},
+		{acceptEncoding: "gzip;q=0", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			if got := Negotiate(tt.acceptEncoding, DefaultEncodings); got != tt.want {
+				t.Fatalf("Negotiate(%q) = %q, want %q", tt.acceptEncoding, got, tt.want)
+			}
+		})
+	}
+}
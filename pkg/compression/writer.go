@@ -0,0 +1,241 @@
+package compression
+
+import (
+	"bytes"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+// DefaultMinSize ответы меньше этого размера не сжимаются: выигрыш меньше накладных расходов
+const DefaultMinSize = 1024
+
+// DefaultContentTypes типы ответов, которые сжимаются. Значение с "/" на конце - префикс
+var DefaultContentTypes = []string{
+	"application/json",
+	"application/problem+json",
+	"application/xml",
+	"application/javascript",
+	"application/x-ndjson",
+	"image/svg+xml",
+	"text/",
+}
+
+// Options настройки сжатия ответов
+type Options struct {
+	// Encodings кодировки в порядке предпочтения сервера, пустой - DefaultEncodings
+	Encodings []string
+	// MinSize минимальный размер ответа для сжатия, 0 - DefaultMinSize
+	MinSize int
+	// ContentTypes сжимаемые типы ответов, пустой - DefaultContentTypes
+	ContentTypes []string
+}
+
+// GetEncodings кодировки в порядке предпочтения
+func (o Options) GetEncodings() []string {
+	if len(o.Encodings) == 0 {
+		return DefaultEncodings
+	}
+
+	return o.Encodings
+}
+
+// GetMinSize минимальный размер ответа для сжатия
+func (o Options) GetMinSize() int {
+	if o.MinSize <= 0 {
+		return DefaultMinSize
+	}
+
+	return o.MinSize
+}
+
+// IsCompressible сжимается ли ответ с Content-Type contentType
+func (o Options) IsCompressible(contentType string) bool {
+	contentTypes := o.ContentTypes
+
+	if len(contentTypes) == 0 {
+		contentTypes = DefaultContentTypes
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+	for _, allowed := range contentTypes {
+		if strings.HasSuffix(allowed, "/") && strings.HasPrefix(mediaType, allowed) || mediaType == allowed {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewWriter ResponseWriter, сжимающий ответ кодировкой encoding. Ответ копится в буфере, пока не наберет
+// MinSize (тогда сжимается) или не закончится (тогда отдается как есть). Flush сразу начинает сжатие - для стриминга
+func NewWriter(w gin.ResponseWriter, encoding string, options Options) *Writer {
+	return &Writer{ResponseWriter: w, encoding: encoding, options: options}
+}
+
+// Writer ResponseWriter со сжатием ответа
+type Writer struct {
+	gin.ResponseWriter
+
+	encoding string
+	options  Options
+	buffer   bytes.Buffer
+	encoder  Encoder
+	// decided решено, сжимать ли ответ; до этого ответ копится в буфере
+	decided bool
+	// headerRequested заголовки запрошены до решения (AbortWithStatus), отправляются вместе с буфером
+	headerRequested bool
+	size            int
+}
+
+func (w *Writer) Write(data []byte) (int, error) {
+	if !w.decided {
+		w.buffer.Write(data)
+		w.size += len(data)
+
+		if w.buffer.Len() < w.options.GetMinSize() {
+			return len(data), nil
+		}
+
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+
+		return len(data), nil
+	}
+
+	w.size += len(data)
+
+	if w.encoder != nil {
+		return w.encoder.Write(data)
+	}
+
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *Writer) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// WriteHeaderNow до решения о сжатии заголовки не отправляются: AbortWithStatus вызывает его до записи тела ошибки,
+// и конверт ошибки сжимается по тем же правилам, что и остальные ответы. Без тела заголовки отправит Close
+func (w *Writer) WriteHeaderNow() {
+	if !w.decided {
+		w.headerRequested = true
+
+		return
+	}
+
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+// Flush стриминг: сжимаем сразу, не дожидаясь MinSize
+func (w *Writer) Flush() {
+	if !w.decided {
+		_ = w.decide(true)
+	}
+
+	if w.encoder != nil {
+		_ = w.encoder.Flush()
+	}
+
+	w.ResponseWriter.Flush()
+}
+
+// Size размер ответа до сжатия
+func (w *Writer) Size() int {
+	if w.size == 0 {
+		return w.ResponseWriter.Size()
+	}
+
+	return w.size
+}
+
+func (w *Writer) Written() bool {
+	return w.size > 0 || w.headerRequested || w.ResponseWriter.Written()
+}
+
+// Close дописывает буфер и завершает сжатие. Вызывается после обработки запроса
+func (w *Writer) Close() error {
+	if !w.decided {
+		// ответ целиком меньше MinSize
+		if err := w.decide(false); err != nil {
+			return err
+		}
+	}
+
+	if w.encoder == nil {
+		if w.headerRequested {
+			w.ResponseWriter.WriteHeaderNow()
+		}
+
+		return nil
+	}
+
+	err := w.encoder.Close()
+	PutEncoder(w.encoding, w.encoder)
+	w.encoder = nil
+
+	return err
+}
+
+// decide решает, сжимать ли ответ, и отправляет накопленный буфер
+func (w *Writer) decide(compress bool) error {
+	w.decided = true
+	header := w.Header()
+
+	if header.Get("Content-Type") == "" && w.buffer.Len() > 0 {
+		// net/http определил бы тип по сжатым данным, поэтому определяем по исходным
+		header.Set("Content-Type", http.DetectContentType(w.buffer.Bytes()))
+	}
+
+	if compress && w.isCompressible() {
+		encoder, err := GetEncoder(w.encoding, w.ResponseWriter)
+
+		if err != nil {
+			return err
+		}
+
+		w.encoder = encoder
+		header.Set("Content-Encoding", w.encoding)
+		header.Del("Content-Length")
+		// ETag описывает несжатое представление
+		if etag := header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+			header.Set("ETag", "W/"+etag)
+		}
+	}
+
+	if w.buffer.Len() == 0 {
+		return nil
+	}
+
+	var err error
+
+	if w.encoder != nil {
+		_, err = w.encoder.Write(w.buffer.Bytes())
+	} else {
+		_, err = w.ResponseWriter.Write(w.buffer.Bytes())
+	}
+
+	w.buffer.Reset()
+
+	return err
+}
+
+func (w *Writer) isCompressible() bool {
+	header := w.Header()
+	status := w.Status()
+
+	if header.Get("Content-Encoding") != "" || status < http.StatusOK || status == http.StatusNoContent || status == http.StatusNotModified {
+		return false
+	}
+
+	// диапазон описывает байты несжатого ответа
+	if status == http.StatusPartialContent || header.Get("Content-Range") != "" {
+		return false
+	}
+
+	return w.options.IsCompressible(header.Get("Content-Type"))
+}
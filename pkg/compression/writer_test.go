@@ -0,0 +1,157 @@
+package compression
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+var largeJson = `{"data":"` + strings.Repeat("a", 2*DefaultMinSize) + `"}`
+
+// serve выполняет handler с ответом через Writer со сжатием gzip
+func serve(t *testing.T, handler gin.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+
+	recorder := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(recorder)
+	writer := NewWriter(c.Writer, Gzip, Options{})
+	c.Writer = writer
+
+	handler(c)
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return recorder
+}
+
+// body тело ответа, распакованное, если оно сжато
+func body(t *testing.T, recorder *httptest.ResponseRecorder) string {
+	t.Helper()
+
+	if recorder.Header().Get("Content-Encoding") != Gzip {
+		return recorder.Body.String()
+	}
+
+	reader, err := gzip.NewReader(recorder.Body)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := io.ReadAll(reader)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name           string
+		handler        gin.HandlerFunc
+		wantStatus     int
+		wantBody       string
+		wantCompressed bool
+	}{
+		{
+			name:       "below min size",
+			handler:    func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(`{"ok":true}`)) },
+			wantStatus: http.StatusOK,
+			wantBody:   `{"ok":true}`,
+		},
+		{
+			name:           "min size",
+			handler:        func(c *gin.Context) { c.Data(http.StatusOK, "application/json", []byte(largeJson)) },
+			wantStatus:     http.StatusOK,
+			wantBody:       largeJson,
+			wantCompressed: true,
+		},
+		{
+			name: "min size in several writes",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Type", "application/json")
+				_, _ = c.Writer.WriteString(largeJson[:DefaultMinSize-1])
+				_, _ = c.Writer.WriteString(largeJson[DefaultMinSize-1:])
+			},
+			wantStatus:     http.StatusOK,
+			wantBody:       largeJson,
+			wantCompressed: true,
+		},
+		{
+			name:       "not compressible content type",
+			handler:    func(c *gin.Context) { c.Data(http.StatusOK, "image/png", []byte(largeJson)) },
+			wantStatus: http.StatusOK,
+			wantBody:   largeJson,
+		},
+		{
+			name:       "partial content",
+			handler:    func(c *gin.Context) { c.Data(http.StatusPartialContent, "application/json", []byte(largeJson)) },
+			wantStatus: http.StatusPartialContent,
+			wantBody:   largeJson,
+		},
+		{
+			name: "content range",
+			handler: func(c *gin.Context) {
+				c.Header("Content-Range", "bytes 0-2059/2060")
+				c.Data(http.StatusOK, "application/json", []byte(largeJson))
+			},
+			wantStatus: http.StatusOK,
+			wantBody:   largeJson,
+		},
+		{
+			// AbortWithStatus отправляет заголовки до записи конверта ошибки
+			name: "error envelope after abort",
+			handler: func(c *gin.Context) {
+				c.AbortWithStatus(http.StatusBadRequest)
+				c.Data(http.StatusBadRequest, "application/json", []byte(largeJson))
+			},
+			wantStatus:     http.StatusBadRequest,
+			wantBody:       largeJson,
+			wantCompressed: true,
+		},
+		{
+			name:       "abort without body",
+			handler:    func(c *gin.Context) { c.AbortWithStatus(http.StatusUnauthorized) },
+			wantStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := serve(t, tt.handler)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if compressed := recorder.Header().Get("Content-Encoding") == Gzip; compressed != tt.wantCompressed {
+				t.Fatalf("compressed = %v, want %v", compressed, tt.wantCompressed)
+			}
+
+			if got := body(t, recorder); got != tt.wantBody {
+				t.Fatalf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWriterFlushCompressesImmediately(t *testing.T) {
+	recorder := serve(t, func(c *gin.Context) {
+		c.Header("Content-Type", "text/event-stream")
+		_, _ = c.Writer.WriteString("data: 1\n\n")
+		c.Writer.Flush()
+	})
+
+	if recorder.Header().Get("Content-Encoding") != Gzip || body(t, recorder) != "data: 1\n\n" {
+		t.Fatalf("stream = %v %q, want compressed event", recorder.Header(), recorder.Body.String())
+	}
+}
@@ -1,9 +1,347 @@
 package config
 
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Значения по умолчанию для таймаутов и лимитов сервера
+const (
+	DefaultServerReadTimeout       = 15
+	DefaultServerReadHeaderTimeout = 10
+	DefaultServerWriteTimeout      = 30
+	DefaultServerIdleTimeout       = 60
+	DefaultServerMaxHeaderBytes    = 1 << 20
+	DefaultSentryFlushTimeout      = 2
+	DefaultCorsMaxAge              = 600
+	DefaultMaxRequestBodySize      = 10 << 20 // 10 MiB, kernel ограничивает тело запроса даже без MAX_REQUEST_BODY_SIZE
+)
+
+// Значения CORS по умолчанию
+const (
+	DefaultCorsAllowedMethods = "GET,POST,PUT,PATCH,DELETE,HEAD,OPTIONS"
+	DefaultCorsAllowedHeaders = "Origin,Content-Type,Accept,Authorization,Accept-Language,Request-Id"
+)
+
+// writeTimeoutMargin запас SERVER_WRITE_TIMEOUT по умолчанию сверх HANDLER_TIMEOUT, чтобы успеть отдать ответ о таймауте
+const writeTimeoutMargin = 5
+
+// TimeoutDisabled значение таймаута, при котором он отключается (например, для long polling)
+const TimeoutDisabled = -1
+
 // HttpConfig Http конфиг
 type HttpConfig struct {
-	SwaggerPrefix  string `mapstructure:"SWAGGER_PREFIX" json:"swagger_prefix"`
-	ServerAddress  string `mapstructure:"SERVER_ADDRESS" json:"server_address"`
-	SentryDsn      string `mapstructure:"SENTRY_DSN"    json:"sentry_dsn"`
-	HandlerTimeout int    `mapstructure:"HANDLER_TIMEOUT"    json:"handler_timeout"`
+	SwaggerPrefix string `mapstructure:"SWAGGER_PREFIX" json:"swagger_prefix"`
+	ServerAddress string `mapstructure:"SERVER_ADDRESS" json:"server_address"`
+	// AdminServerAddress адрес служебного сервера (метрики, pprof, health checks). Если не задан - служебный сервер не поднимается
+	AdminServerAddress string `mapstructure:"ADMIN_SERVER_ADDRESS" json:"admin_server_address"`
+	SentryDsn          string `mapstructure:"SENTRY_DSN"    json:"sentry_dsn"`
+	HandlerTimeout     int    `mapstructure:"HANDLER_TIMEOUT"    json:"handler_timeout"`
+	TlsCertFile        string `mapstructure:"TLS_CERT_FILE"    json:"tls_cert_file"`
+	TlsKeyFile         string `mapstructure:"TLS_KEY_FILE"    json:"tls_key_file"`
+	TlsClientCaFile    string `mapstructure:"TLS_CLIENT_CA_FILE"    json:"tls_client_ca_file"`
+	TlsClientAuth      string `mapstructure:"TLS_CLIENT_AUTH"    json:"tls_client_auth"`
+	// Таймауты сервера в секундах: 0 - значение по умолчанию, -1 - без таймаута
+	ServerReadTimeout       int `mapstructure:"SERVER_READ_TIMEOUT"    json:"server_read_timeout"`
+	ServerReadHeaderTimeout int `mapstructure:"SERVER_READ_HEADER_TIMEOUT"    json:"server_read_header_timeout"`
+	ServerWriteTimeout      int `mapstructure:"SERVER_WRITE_TIMEOUT"    json:"server_write_timeout"`
+	ServerIdleTimeout       int `mapstructure:"SERVER_IDLE_TIMEOUT"    json:"server_idle_timeout"`
+	// ServerMaxHeaderBytes максимальный размер заголовков запроса в байтах, 0 - значение по умолчанию
+	ServerMaxHeaderBytes int `mapstructure:"SERVER_MAX_HEADER_BYTES"    json:"server_max_header_bytes"`
+	// MaxRequestBodySize максимальный размер тела запроса в байтах: 0 - значение по умолчанию (10 MiB), -1 - без ограничения
+	MaxRequestBodySize int64 `mapstructure:"MAX_REQUEST_BODY_SIZE"    json:"max_request_body_size"`
+	// ShutdownDelay пауза в секундах между провалом readiness и остановкой сервера,
+	// за которую балансировщик успевает вывести инстанс
+	ShutdownDelay int `mapstructure:"SHUTDOWN_DELAY"    json:"shutdown_delay"`
+	// ShutdownGracePeriod сколько секунд ждем запросы в обработке, после чего отменяем их context.
+	// 0 - ждем до конца таймаута остановки приложения
+	ShutdownGracePeriod int `mapstructure:"SHUTDOWN_GRACE_PERIOD"    json:"shutdown_grace_period"`
+	// SentryFlushTimeout сколько секунд ждем отправки событий в sentry при остановке
+	SentryFlushTimeout int `mapstructure:"SENTRY_FLUSH_TIMEOUT"    json:"sentry_flush_timeout"`
+	// MetricsExemplars привязывать к бакетам гистограммы времени ответа exemplars с request id и trace id
+	MetricsExemplars bool `mapstructure:"METRICS_EXEMPLARS"    json:"metrics_exemplars"`
+	// MetricsIncludeDefaultRegistry отдавать в /metrics и метрики глобального prometheus.DefaultRegisterer
+	// (сторонние библиотеки, старый код сервиса). По умолчанию только registry kernel
+	MetricsIncludeDefaultRegistry bool `mapstructure:"METRICS_INCLUDE_DEFAULT_REGISTRY"    json:"metrics_include_default_registry"`
+	// TracingEnabled включает трейсинг OpenTelemetry
+	TracingEnabled bool `mapstructure:"TRACING_ENABLED"    json:"tracing_enabled"`
+	// TracingOtlpEndpoint адрес OTLP/HTTP коллектора, например http://otel-collector:4318.
+	// Если не задан - используются стандартные OTEL_EXPORTER_OTLP_*
+	TracingOtlpEndpoint string `mapstructure:"TRACING_OTLP_ENDPOINT"    json:"tracing_otlp_endpoint"`
+	// TracingSampleRatio доля сэмплируемых трейсов от 0 до 1, 0 - значение по умолчанию (1)
+	TracingSampleRatio float64 `mapstructure:"TRACING_SAMPLE_RATIO"    json:"tracing_sample_ratio"`
+	// Ключи проверки JWT: секрет HS256, PEM файл публичного ключа RSA/ECDSA, JWKS по url или из файла
+	JwtHmacSecret    string `mapstructure:"JWT_HMAC_SECRET"    json:"-"`
+	JwtPublicKeyFile string `mapstructure:"JWT_PUBLIC_KEY_FILE"    json:"jwt_public_key_file"`
+	JwtJwksUrl       string `mapstructure:"JWT_JWKS_URL"    json:"jwt_jwks_url"`
+	JwtJwksFile      string `mapstructure:"JWT_JWKS_FILE"    json:"jwt_jwks_file"`
+	// JwtJwksRefreshInterval как часто перечитывать JWKS в секундах, 0 - значение по умолчанию (15 минут)
+	JwtJwksRefreshInterval int `mapstructure:"JWT_JWKS_REFRESH_INTERVAL"    json:"jwt_jwks_refresh_interval"`
+	// JwtIssuer ожидаемый iss токена
+	JwtIssuer string `mapstructure:"JWT_ISSUER"    json:"jwt_issuer"`
+	// JwtAudience допустимые aud токена через запятую
+	JwtAudience string `mapstructure:"JWT_AUDIENCE"    json:"jwt_audience"`
+	// ApiKeys клиенты API ключей: "client1:<sha256 hex>,client2:<sha256 hex>"
+	ApiKeys string `mapstructure:"API_KEYS"    json:"-"`
+	// ApiKeyHeader заголовок с API ключом, по умолчанию X-Api-Key
+	ApiKeyHeader string `mapstructure:"API_KEY_HEADER"    json:"api_key_header"`
+	// ApiKeyQueryParam query параметр с API ключом, если ключ не пришел в заголовке. Пустой - не используется
+	ApiKeyQueryParam string `mapstructure:"API_KEY_QUERY_PARAM"    json:"api_key_query_param"`
+	// CorsAllowedOrigins разрешенные origin через запятую: "*" - любой, "https://*.example.com" - поддомены.
+	// Пустой - CORS не обрабатывается
+	CorsAllowedOrigins string `mapstructure:"CORS_ALLOWED_ORIGINS"    json:"cors_allowed_origins"`
+	// CorsAllowedMethods разрешенные методы через запятую, пустой - DefaultCorsAllowedMethods
+	CorsAllowedMethods string `mapstructure:"CORS_ALLOWED_METHODS"    json:"cors_allowed_methods"`
+	// CorsAllowedHeaders разрешенные заголовки запроса через запятую, "*" - любые запрошенные, пустой - DefaultCorsAllowedHeaders
+	CorsAllowedHeaders string `mapstructure:"CORS_ALLOWED_HEADERS"    json:"cors_allowed_headers"`
+	// CorsExposedHeaders заголовки ответа, доступные скрипту, через запятую
+	CorsExposedHeaders string `mapstructure:"CORS_EXPOSED_HEADERS"    json:"cors_exposed_headers"`
+	// CorsAllowCredentials разрешить запросы с cookies и авторизацией
+	CorsAllowCredentials bool `mapstructure:"CORS_ALLOW_CREDENTIALS"    json:"cors_allow_credentials"`
+	// CorsMaxAge сколько секунд браузер кеширует preflight: 0 - значение по умолчанию, -1 - не кешировать
+	CorsMaxAge int `mapstructure:"CORS_MAX_AGE"    json:"cors_max_age"`
+}
+
+// IsTlsEnabled Включен ли TLS (заданы сертификат и ключ)
+func (c *HttpConfig) IsTlsEnabled() bool {
+	return c.TlsCertFile != "" && c.TlsKeyFile != ""
+}
+
+// IsAdminServerEnabled Поднимать ли отдельный служебный сервер
+func (c *HttpConfig) IsAdminServerEnabled() bool {
+	return c.AdminServerAddress != ""
+}
+
+// GetReadTimeout Таймаут чтения запроса
+func (c *HttpConfig) GetReadTimeout() time.Duration {
+	return secondsOrDefault(c.ServerReadTimeout, DefaultServerReadTimeout)
+}
+
+// GetReadHeaderTimeout Таймаут чтения заголовков запроса
+func (c *HttpConfig) GetReadHeaderTimeout() time.Duration {
+	return secondsOrDefault(c.ServerReadHeaderTimeout, DefaultServerReadHeaderTimeout)
+}
+
+// GetWriteTimeout Таймаут записи ответа. Если не задан, он не меньше HANDLER_TIMEOUT с запасом
+func (c *HttpConfig) GetWriteTimeout() time.Duration {
+	if c.ServerWriteTimeout == 0 && c.HandlerTimeout > 0 {
+		return time.Duration(max(DefaultServerWriteTimeout, c.HandlerTimeout+writeTimeoutMargin)) * time.Second
+	}
+
+	return secondsOrDefault(c.ServerWriteTimeout, DefaultServerWriteTimeout)
+}
+
+// GetIdleTimeout Таймаут простоя keep-alive соединения
+func (c *HttpConfig) GetIdleTimeout() time.Duration {
+	return secondsOrDefault(c.ServerIdleTimeout, DefaultServerIdleTimeout)
+}
+
+// GetMaxHeaderBytes Максимальный размер заголовков запроса
+func (c *HttpConfig) GetMaxHeaderBytes() int {
+	if c.ServerMaxHeaderBytes == 0 {
+		return DefaultServerMaxHeaderBytes
+	}
+
+	return c.ServerMaxHeaderBytes
+}
+
+// GetMaxRequestBodySize Максимальный размер тела запроса, 0 - без ограничения
+func (c *HttpConfig) GetMaxRequestBodySize() int64 {
+	switch {
+	case c.MaxRequestBodySize == 0:
+		return DefaultMaxRequestBodySize
+	case c.MaxRequestBodySize < 0:
+		return 0
+	default:
+		return c.MaxRequestBodySize
+	}
+}
+
+// GetShutdownDelay Пауза перед остановкой сервера
+func (c *HttpConfig) GetShutdownDelay() time.Duration {
+	return time.Duration(c.ShutdownDelay) * time.Second
+}
+
+// GetShutdownGracePeriod Сколько ждем запросы в обработке перед отменой их context
+func (c *HttpConfig) GetShutdownGracePeriod() time.Duration {
+	return time.Duration(c.ShutdownGracePeriod) * time.Second
+}
+
+// GetSentryFlushTimeout Таймаут отправки событий в sentry при остановке
+func (c *HttpConfig) GetSentryFlushTimeout() time.Duration {
+	return secondsOrDefault(c.SentryFlushTimeout, DefaultSentryFlushTimeout)
+}
+
+// GetTracingSampleRatio Доля сэмплируемых трейсов
+func (c *HttpConfig) GetTracingSampleRatio() float64 {
+	if c.TracingSampleRatio == 0 {
+		return 1
+	}
+
+	return c.TracingSampleRatio
+}
+
+// IsJwtEnabled Задан ли хотя бы один источник ключей проверки JWT
+func (c *HttpConfig) IsJwtEnabled() bool {
+	return c.JwtHmacSecret != "" || c.JwtPublicKeyFile != "" || c.JwtJwksUrl != "" || c.JwtJwksFile != ""
+}
+
+// IsApiKeyEnabled Заданы ли клиенты API ключей
+func (c *HttpConfig) IsApiKeyEnabled() bool {
+	return strings.TrimSpace(c.ApiKeys) != ""
+}
+
+// IsCorsEnabled Заданы ли разрешенные CORS origin
+func (c *HttpConfig) IsCorsEnabled() bool {
+	return len(c.GetCorsAllowedOrigins()) > 0
+}
+
+// GetCorsAllowedOrigins Разрешенные CORS origin
+func (c *HttpConfig) GetCorsAllowedOrigins() []string {
+	return splitList(c.CorsAllowedOrigins)
+}
+
+// GetCorsAllowedMethods Разрешенные CORS методы
+func (c *HttpConfig) GetCorsAllowedMethods() []string {
+	if strings.TrimSpace(c.CorsAllowedMethods) == "" {
+		return splitList(DefaultCorsAllowedMethods)
+	}
+
+	return splitList(strings.ToUpper(c.CorsAllowedMethods))
+}
+
+// GetCorsAllowedHeaders Разрешенные CORS заголовки запроса
+func (c *HttpConfig) GetCorsAllowedHeaders() []string {
+	if strings.TrimSpace(c.CorsAllowedHeaders) == "" {
+		return splitList(DefaultCorsAllowedHeaders)
+	}
+
+	return splitList(c.CorsAllowedHeaders)
+}
+
+// GetCorsExposedHeaders Заголовки ответа, доступные скрипту
+func (c *HttpConfig) GetCorsExposedHeaders() []string {
+	return splitList(c.CorsExposedHeaders)
+}
+
+// GetCorsMaxAge Сколько браузер кеширует preflight, меньше 0 - не кешировать (cors.Options.MaxAge)
+func (c *HttpConfig) GetCorsMaxAge() time.Duration {
+	if c.CorsMaxAge < 0 {
+		return -time.Second
+	}
+
+	return secondsOrDefault(c.CorsMaxAge, DefaultCorsMaxAge)
+}
+
+// GetJwtAudience Допустимые aud токена
+func (c *HttpConfig) GetJwtAudience() []string {
+	return splitList(c.JwtAudience)
+}
+
+// GetJwtJwksRefreshInterval Как часто перечитывать JWKS, 0 - значение по умолчанию
+func (c *HttpConfig) GetJwtJwksRefreshInterval() time.Duration {
+	return time.Duration(c.JwtJwksRefreshInterval) * time.Second
+}
+
+// Validate Проверяет корректность таймаутов и лимитов сервера
+func (c *HttpConfig) Validate() error {
+	timeouts := map[string]int{
+		"SERVER_READ_TIMEOUT":        c.ServerReadTimeout,
+		"SERVER_READ_HEADER_TIMEOUT": c.ServerReadHeaderTimeout,
+		"SERVER_WRITE_TIMEOUT":       c.ServerWriteTimeout,
+		"SERVER_IDLE_TIMEOUT":        c.ServerIdleTimeout,
+	}
+
+	for name, value := range timeouts {
+		if value < TimeoutDisabled {
+			return fmt.Errorf("%s must be >= %d, got %d", name, TimeoutDisabled, value)
+		}
+	}
+
+	if c.HandlerTimeout < 0 {
+		return fmt.Errorf("HANDLER_TIMEOUT must be >= 0, got %d", c.HandlerTimeout)
+	}
+
+	// CA клиентов без сертификата сервера молча отключил бы mTLS
+	if !c.IsTlsEnabled() && (c.TlsClientCaFile != "" || c.TlsClientAuth != "") {
+		return errors.New("mTLS (TLS_CLIENT_CA_FILE, TLS_CLIENT_AUTH) requires server TLS_CERT_FILE and TLS_KEY_FILE")
+	}
+
+	if (c.TlsCertFile == "") != (c.TlsKeyFile == "") {
+		return errors.New("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	if c.IsAdminServerEnabled() && c.AdminServerAddress == c.ServerAddress {
+		return errors.New("ADMIN_SERVER_ADDRESS must differ from SERVER_ADDRESS")
+	}
+
+	if c.ShutdownDelay < 0 || c.ShutdownGracePeriod < 0 || c.SentryFlushTimeout < 0 {
+		return errors.New("SHUTDOWN_DELAY, SHUTDOWN_GRACE_PERIOD and SENTRY_FLUSH_TIMEOUT must be >= 0")
+	}
+
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		return fmt.Errorf("TRACING_SAMPLE_RATIO must be between 0 and 1, got %v", c.TracingSampleRatio)
+	}
+
+	if c.JwtJwksRefreshInterval < 0 {
+		return fmt.Errorf("JWT_JWKS_REFRESH_INTERVAL must be >= 0, got %d", c.JwtJwksRefreshInterval)
+	}
+
+	if c.CorsMaxAge < TimeoutDisabled {
+		return fmt.Errorf("CORS_MAX_AGE must be >= %d, got %d", TimeoutDisabled, c.CorsMaxAge)
+	}
+
+	// браузер не примет "*" вместе с credentials, а отражать любой origin с cookies небезопасно
+	if c.CorsAllowCredentials && slices.Contains(c.GetCorsAllowedOrigins(), "*") {
+		return errors.New("CORS_ALLOW_CREDENTIALS cannot be used with CORS_ALLOWED_ORIGINS=*")
+	}
+
+	if c.MaxRequestBodySize < TimeoutDisabled {
+		return fmt.Errorf("MAX_REQUEST_BODY_SIZE must be >= %d, got %d", TimeoutDisabled, c.MaxRequestBodySize)
+	}
+
+	if c.ServerMaxHeaderBytes < 0 {
+		return fmt.Errorf("SERVER_MAX_HEADER_BYTES must be >= 0, got %d", c.ServerMaxHeaderBytes)
+	}
+
+	readTimeout := c.GetReadTimeout()
+
+	if readTimeout > 0 && c.GetReadHeaderTimeout() > readTimeout {
+		return errors.New("SERVER_READ_HEADER_TIMEOUT must not be greater than SERVER_READ_TIMEOUT")
+	}
+
+	// хендлер должен успеть отдать ответ до того, как сервер закроет соединение.
+	// Проверяется только явно заданный SERVER_WRITE_TIMEOUT, значение по умолчанию подстраивается под HANDLER_TIMEOUT
+	if c.ServerWriteTimeout > 0 && c.HandlerTimeout > 0 && c.HandlerTimeout >= c.ServerWriteTimeout {
+		return errors.New("HANDLER_TIMEOUT must be less than SERVER_WRITE_TIMEOUT")
+	}
+
+	return nil
+}
+
+func secondsOrDefault(value int, def int) time.Duration {
+	switch {
+	case value == 0:
+		return time.Duration(def) * time.Second
+	case value < 0:
+		return 0
+	default:
+		return time.Duration(value) * time.Second
+	}
+}
+
+// splitList значения через запятую без пустых
+func splitList(value string) []string {
+	items := make([]string, 0)
+
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
 }
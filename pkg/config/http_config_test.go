@@ -0,0 +1,37 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHttpConfigValidateTls(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  HttpConfig
+		wantErr string
+	}{
+		{name: "without tls", config: HttpConfig{}},
+		{name: "tls", config: HttpConfig{TlsCertFile: "tls.crt", TlsKeyFile: "tls.key"}},
+		{name: "mtls", config: HttpConfig{TlsCertFile: "tls.crt", TlsKeyFile: "tls.key", TlsClientCaFile: "ca.crt", TlsClientAuth: "require_and_verify"}},
+		{name: "cert without key", config: HttpConfig{TlsCertFile: "tls.crt"}, wantErr: "must be set together"},
+		{name: "key without cert", config: HttpConfig{TlsKeyFile: "tls.key"}, wantErr: "must be set together"},
+		{name: "client ca without server cert", config: HttpConfig{TlsClientCaFile: "ca.crt"}, wantErr: "mTLS"},
+		{name: "client auth without server cert", config: HttpConfig{TlsClientAuth: "require"}, wantErr: "mTLS"},
+		{name: "client ca with cert only", config: HttpConfig{TlsCertFile: "tls.crt", TlsClientCaFile: "ca.crt"}, wantErr: "mTLS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.config.Validate()
+
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Validate() error = %v, want nil", err)
+			}
+
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Fatalf("Validate() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
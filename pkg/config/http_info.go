@@ -1,10 +1,14 @@
 package config
 
-import "github.com/google/uuid"
+import (
+	"github.com/google/uuid"
+	"net/http"
+)
 
 // HttpInfo Данные http
 type HttpInfo struct {
-	RequestId     string
+	RequestId string
+	// AuthToken bearer токен из заголовка Authorization
 	AuthToken     string
 	RequestScheme string
 	RequestHost   string
@@ -12,8 +16,69 @@ type HttpInfo struct {
 	RequestUrl    string
 	CacheControl  string
 	LanguageCode  string
+	// Заголовки идентификации
+	CityId           int
+	UserId           int
+	AppsflyerId      string
+	CurrentCompanyId int
+	CompanyId        int
+	CompanyIds       []int
+	Iin              string
+	// TraceId и SpanId текущего спана (если включен трейсинг)
+	TraceId string
+	SpanId  string
+	// ClientCertSubject Subject проверенного клиентского сертификата (mTLS)
+	ClientCertSubject string
+	// ClientCertCommonName CommonName проверенного клиентского сертификата (mTLS)
+	ClientCertCommonName string
+	// ApiClientId клиент, аутентифицированный по API ключу
+	ApiClientId string
+	// Headers заголовки входящего запроса (например, для прокидывания в исходящие запросы)
+	Headers http.Header `json:"-"`
+	// Values значения, добавленные экстракторами сервиса (доменные заголовки)
+	Values map[string]any
 }
 
 func (s *HttpInfo) GenerateRequestId() {
 	s.RequestId = uuid.New().String()
 }
+
+// HasClientCert Пришел ли запрос с проверенным клиентским сертификатом
+func (s *HttpInfo) HasClientCert() bool {
+	return s.ClientCertSubject != ""
+}
+
+// SetValue сохраняет значение доменного заголовка (вызывается из экстракторов RequestInfoMiddleware)
+func (s *HttpInfo) SetValue(key string, value any) {
+	if s.Values == nil {
+		s.Values = make(map[string]any)
+	}
+
+	s.Values[key] = value
+}
+
+// GetValue значение, добавленное экстрактором
+func (s *HttpInfo) GetValue(key string) (any, bool) {
+	value, ok := s.Values[key]
+
+	return value, ok
+}
+
+// GetHttpInfoValue типизированное значение, добавленное экстрактором. false - если значения нет или тип другой
+func GetHttpInfoValue[T any](httpInfo *HttpInfo, key string) (T, bool) {
+	var zero T
+
+	if httpInfo == nil {
+		return zero, false
+	}
+
+	value, ok := httpInfo.GetValue(key)
+
+	if !ok {
+		return zero, false
+	}
+
+	typed, ok := value.(T)
+
+	return typed, ok
+}
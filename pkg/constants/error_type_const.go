@@ -3,12 +3,18 @@ package constants
 import "net/http"
 
 const (
-	NotFound            = "not_found"
-	AccessDenied        = "access_denied"
-	OperationFailed     = "operation_failed"
-	IncorrectParams     = "incorrect_parameters"
-	ValidationError     = "validation_error"
-	InternalServerError = "internal_server_error"
+	NotFound             = "not_found"
+	AccessDenied         = "access_denied"
+	Unauthorized         = "unauthorized"
+	OperationFailed      = "operation_failed"
+	IncorrectParams      = "incorrect_parameters"
+	ValidationError      = "validation_error"
+	TooManyRequests      = "too_many_requests"
+	PayloadTooLarge      = "payload_too_large"
+	UnsupportedMediaType = "unsupported_media_type"
+	UnsupportedEncoding  = "unsupported_encoding" // 415 из-за Content-Encoding, задается DecompressionMiddleware явно
+	ServiceUnavailable   = "service_unavailable"
+	InternalServerError  = "internal_server_error"
 )
 
 // GetErrorTypeByStatusCode возвращает тип ошибки для респонза по хттп статус коду
@@ -20,12 +26,22 @@ func GetErrorTypeByStatusCode(statusCode int) string {
 		return InternalServerError
 	case http.StatusForbidden:
 		return AccessDenied
+	case http.StatusUnauthorized:
+		return Unauthorized
 	case http.StatusNotAcceptable:
 		return OperationFailed
 	case http.StatusNotFound:
 		return NotFound
 	case http.StatusBadRequest:
 		return IncorrectParams
+	case http.StatusRequestEntityTooLarge:
+		return PayloadTooLarge
+	case http.StatusUnsupportedMediaType:
+		return UnsupportedMediaType
+	case http.StatusTooManyRequests:
+		return TooManyRequests
+	case http.StatusServiceUnavailable:
+		return ServiceUnavailable
 	default:
 		return InternalServerError
 	}
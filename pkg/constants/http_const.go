@@ -1,3 +1,6 @@
 package constants
 
-const HttpInfoKey string = "http_info"
+const (
+	HttpInfoKey  string = "http_info"
+	JwtClaimsKey string = "jwt_claims"
+)
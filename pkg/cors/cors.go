@@ -0,0 +1,219 @@
+package cors
+
+import (
+	"github.com/exgamer/gosdk-http-core/pkg/config"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Options настройки CORS
+type Options struct {
+	// AllowedOrigins разрешенные origin: "*" - любой, "https://*.example.com" - любой поддомен example.com
+	AllowedOrigins []string
+	AllowedMethods []string
+	// AllowedHeaders разрешенные заголовки запроса, "*" - любые запрошенные
+	AllowedHeaders []string
+	// ExposedHeaders заголовки ответа, доступные скрипту
+	ExposedHeaders   []string
+	AllowCredentials bool
+	// MaxAge сколько браузер кеширует preflight: меньше 0 - не кешировать (Access-Control-Max-Age: 0),
+	// 0 - не задано, заголовок не отправляется и браузер применяет свое значение по умолчанию (5 секунд)
+	MaxAge time.Duration
+}
+
+// NewOptionsFromConfig настройки CORS из HttpConfig
+func NewOptionsFromConfig(httpConfig *config.HttpConfig) Options {
+	return Options{
+		AllowedOrigins:   httpConfig.GetCorsAllowedOrigins(),
+		AllowedMethods:   httpConfig.GetCorsAllowedMethods(),
+		AllowedHeaders:   httpConfig.GetCorsAllowedHeaders(),
+		ExposedHeaders:   httpConfig.GetCorsExposedHeaders(),
+		AllowCredentials: httpConfig.CorsAllowCredentials,
+		MaxAge:           httpConfig.GetCorsMaxAge(),
+	}
+}
+
+// Middleware обрабатывает CORS. Preflight запрос (OPTIONS с Access-Control-Request-Method) отвечается сразу
+// 204 без вызова хендлеров, поэтому middleware подключается раньше FormattedResponseMiddleware.
+// Запрос с неразрешенного origin выполняется без CORS заголовков - ответ не отдаст браузер,
+// preflight с неразрешенного origin, методом или заголовками отклоняется с 403
+func Middleware(options Options) gin.HandlerFunc {
+	policy := newPolicy(options)
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+
+		if origin == "" {
+			c.Next()
+
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+
+		isPreflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		if isPreflight {
+			header.Add("Vary", "Access-Control-Request-Method")
+			header.Add("Vary", "Access-Control-Request-Headers")
+		}
+
+		requestedHeaders := c.GetHeader("Access-Control-Request-Headers")
+		isAllowedOrigin := policy.isAllowedOrigin(origin)
+
+		if isPreflight && (!isAllowedOrigin || !policy.isAllowedPreflight(c.GetHeader("Access-Control-Request-Method"), requestedHeaders)) {
+			c.AbortWithStatus(http.StatusForbidden)
+
+			return
+		}
+
+		if !isAllowedOrigin {
+			c.Next()
+
+			return
+		}
+
+		if policy.allowAnyOrigin && !options.AllowCredentials {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+
+		if options.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !isPreflight {
+			if policy.exposedHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", policy.exposedHeaders)
+			}
+
+			c.Next()
+
+			return
+		}
+
+		header.Set("Access-Control-Allow-Methods", policy.allowedMethods)
+
+		if policy.allowAnyHeader {
+			if requestedHeaders != "" {
+				header.Set("Access-Control-Allow-Headers", requestedHeaders)
+			}
+		} else {
+			header.Set("Access-Control-Allow-Headers", policy.allowedHeaders)
+		}
+
+		switch {
+		case options.MaxAge < 0:
+			header.Set("Access-Control-Max-Age", "0")
+		case options.MaxAge > 0:
+			header.Set("Access-Control-Max-Age", strconv.Itoa(int(options.MaxAge.Seconds())))
+		}
+
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// policy подготовленные для проверки настройки
+type policy struct {
+	allowAnyOrigin bool
+	allowAnyHeader bool
+	origins        map[string]struct{}
+	// wildcards шаблоны вида "https://*.example.com", разбитые по "*"
+	wildcards      [][2]string
+	headers        map[string]struct{}
+	methods        []string
+	allowedMethods string
+	allowedHeaders string
+	exposedHeaders string
+}
+
+func newPolicy(options Options) *policy {
+	p := &policy{
+		origins:        make(map[string]struct{}),
+		headers:        make(map[string]struct{}),
+		methods:        make([]string, 0, len(options.AllowedMethods)),
+		allowedMethods: strings.Join(options.AllowedMethods, ", "),
+		allowedHeaders: strings.Join(options.AllowedHeaders, ", "),
+		exposedHeaders: strings.Join(options.ExposedHeaders, ", "),
+	}
+
+	for _, origin := range options.AllowedOrigins {
+		origin = strings.ToLower(origin)
+
+		switch {
+		case origin == "*":
+			p.allowAnyOrigin = true
+		case strings.Contains(origin, "*"):
+			prefix, suffix, _ := strings.Cut(origin, "*")
+			p.wildcards = append(p.wildcards, [2]string{prefix, suffix})
+		default:
+			p.origins[origin] = struct{}{}
+		}
+	}
+
+	for _, method := range options.AllowedMethods {
+		p.methods = append(p.methods, strings.ToUpper(method))
+	}
+
+	for _, header := range options.AllowedHeaders {
+		if header == "*" {
+			p.allowAnyHeader = true
+		}
+
+		p.headers[http.CanonicalHeaderKey(header)] = struct{}{}
+	}
+
+	return p
+}
+
+func (p *policy) isAllowedOrigin(origin string) bool {
+	if p.allowAnyOrigin {
+		return true
+	}
+
+	origin = strings.ToLower(origin)
+
+	if _, ok := p.origins[origin]; ok {
+		return true
+	}
+
+	for _, wildcard := range p.wildcards {
+		prefix, suffix := wildcard[0], wildcard[1]
+
+		// поддомен не может быть пустым и содержать путь или порт
+		if len(origin) > len(prefix)+len(suffix) && strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) &&
+			!strings.ContainsAny(origin[len(prefix):len(origin)-len(suffix)], "/:") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *policy) isAllowedPreflight(method string, requestedHeaders string) bool {
+	return slices.Contains(p.methods, strings.ToUpper(method)) && p.isAllowedHeaders(requestedHeaders)
+}
+
+func (p *policy) isAllowedHeaders(requestedHeaders string) bool {
+	if p.allowAnyHeader {
+		return true
+	}
+
+	for _, header := range strings.Split(requestedHeaders, ",") {
+		if header = strings.TrimSpace(header); header == "" {
+			continue
+		}
+
+		if _, ok := p.headers[http.CanonicalHeaderKey(header)]; !ok {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,244 @@
+package cors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/exgamer/gosdk-http-core/pkg/config"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+var testOptions = Options{
+	AllowedOrigins: []string{"https://app.example.com", "https://*.example.org"},
+	AllowedMethods: []string{"GET", "POST"},
+	AllowedHeaders: []string{"Content-Type", "Authorization"},
+	ExposedHeaders: []string{"Request-Id"},
+	MaxAge:         10 * time.Minute,
+}
+
+// serve выполняет запрос через cors.Middleware, handlerCalled - дошел ли запрос до хендлера
+func serve(options Options, request *http.Request) (recorder *httptest.ResponseRecorder, handlerCalled bool) {
+	router := gin.New()
+	router.Use(Middleware(options))
+	handler := func(c *gin.Context) {
+		handlerCalled = true
+		c.Status(http.StatusOK)
+	}
+	router.GET("/orders", handler)
+	router.OPTIONS("/orders", handler)
+
+	recorder = httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	return recorder, handlerCalled
+}
+
+func preflight(origin, method, headers string) *http.Request {
+	request := httptest.NewRequest(http.MethodOptions, "/orders", nil)
+	request.Header.Set("Origin", origin)
+	request.Header.Set("Access-Control-Request-Method", method)
+
+	if headers != "" {
+		request.Header.Set("Access-Control-Request-Headers", headers)
+	}
+
+	return request
+}
+
+func TestPreflight(t *testing.T) {
+	tests := []struct {
+		name        string
+		request     *http.Request
+		wantStatus  int
+		wantOrigin  string
+		wantMethods string
+	}{
+		{
+			name:        "allowed",
+			request:     preflight("https://app.example.com", "POST", "content-type, authorization"),
+			wantStatus:  http.StatusNoContent,
+			wantOrigin:  "https://app.example.com",
+			wantMethods: "GET, POST",
+		},
+		{name: "origin is not allowed", request: preflight("https://evil.com", "POST", ""), wantStatus: http.StatusForbidden},
+		{name: "method is not allowed", request: preflight("https://app.example.com", "DELETE", ""), wantStatus: http.StatusForbidden},
+		{name: "header is not allowed", request: preflight("https://app.example.com", "POST", "X-Debug"), wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, handlerCalled := serve(testOptions, tt.request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if handlerCalled {
+				t.Fatal("preflight reached handler")
+			}
+
+			header := recorder.Header()
+
+			if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+
+			if got := header.Get("Access-Control-Allow-Methods"); got != tt.wantMethods {
+				t.Fatalf("Access-Control-Allow-Methods = %q, want %q", got, tt.wantMethods)
+			}
+
+			if tt.wantStatus == http.StatusNoContent && header.Get("Access-Control-Max-Age") != "600" {
+				t.Fatalf("Access-Control-Max-Age = %q, want 600", header.Get("Access-Control-Max-Age"))
+			}
+
+			wantVary := []string{"Origin", "Access-Control-Request-Method", "Access-Control-Request-Headers"}
+
+			if got := header.Values("Vary"); !slices.Equal(got, wantVary) {
+				t.Fatalf("Vary = %v, want %v", got, wantVary)
+			}
+		})
+	}
+}
+
+func TestWildcardSubdomains(t *testing.T) {
+	tests := []struct {
+		origin  string
+		allowed bool
+	}{
+		{origin: "https://shop.example.org", allowed: true},
+		{origin: "https://eu.shop.example.org", allowed: true},
+		{origin: "https://SHOP.example.org", allowed: true},
+		{origin: "https://example.org"},
+		{origin: "https://.example.org"},
+		{origin: "http://shop.example.org"},
+		{origin: "https://shop.example.org.evil.com"},
+		{origin: "https://evil.com/.example.org"},
+		{origin: "https://evil.com:443.example.org"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			request.Header.Set("Origin", tt.origin)
+			recorder, handlerCalled := serve(testOptions, request)
+
+			if !handlerCalled {
+				t.Fatal("request did not reach handler")
+			}
+
+			if got := recorder.Header().Get("Access-Control-Allow-Origin") != ""; got != tt.allowed {
+				t.Fatalf("allowed = %v, want %v", got, tt.allowed)
+			}
+		})
+	}
+}
+
+func TestSimpleRequest(t *testing.T) {
+	tests := []struct {
+		name            string
+		options         Options
+		origin          string
+		wantOrigin      string
+		wantCredentials string
+		wantExposed     string
+	}{
+		{
+			name:        "allowed origin",
+			options:     testOptions,
+			origin:      "https://app.example.com",
+			wantOrigin:  "https://app.example.com",
+			wantExposed: "Request-Id",
+		},
+		{name: "any origin", options: Options{AllowedOrigins: []string{"*"}}, origin: "https://app.example.com", wantOrigin: "*"},
+		{
+			// с credentials браузер не примет "*", поэтому origin отражается
+			name:            "credentials with reflected origin",
+			options:         Options{AllowedOrigins: []string{"*"}, AllowCredentials: true},
+			origin:          "https://app.example.com",
+			wantOrigin:      "https://app.example.com",
+			wantCredentials: "true",
+		},
+		{name: "origin is not allowed", options: testOptions, origin: "https://evil.com"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/orders", nil)
+			request.Header.Set("Origin", tt.origin)
+			recorder, handlerCalled := serve(tt.options, request)
+			header := recorder.Header()
+
+			if !handlerCalled || recorder.Code != http.StatusOK {
+				t.Fatalf("status = %d, handler called = %v, want 200 from handler", recorder.Code, handlerCalled)
+			}
+
+			if got := header.Get("Access-Control-Allow-Origin"); got != tt.wantOrigin {
+				t.Fatalf("Access-Control-Allow-Origin = %q, want %q", got, tt.wantOrigin)
+			}
+
+			if got := header.Get("Access-Control-Allow-Credentials"); got != tt.wantCredentials {
+				t.Fatalf("Access-Control-Allow-Credentials = %q, want %q", got, tt.wantCredentials)
+			}
+
+			if got := header.Get("Access-Control-Expose-Headers"); got != tt.wantExposed {
+				t.Fatalf("Access-Control-Expose-Headers = %q, want %q", got, tt.wantExposed)
+			}
+
+			// ответ зависит от origin, кеши не должны отдавать его другому origin
+			if got := header.Values("Vary"); !slices.Equal(got, []string{"Origin"}) {
+				t.Fatalf("Vary = %v, want [Origin]", got)
+			}
+		})
+	}
+}
+
+func TestWithoutOrigin(t *testing.T) {
+	recorder, handlerCalled := serve(testOptions, httptest.NewRequest(http.MethodGet, "/orders", nil))
+
+	if !handlerCalled || recorder.Header().Get("Access-Control-Allow-Origin") != "" || recorder.Header().Get("Vary") != "" {
+		t.Fatalf("request without Origin got CORS headers: %v", recorder.Header())
+	}
+}
+
+func TestMaxAgeFromConfig(t *testing.T) {
+	tests := []struct {
+		name       string
+		corsMaxAge int
+		want       string
+	}{
+		{name: "not set", corsMaxAge: 0, want: "600"},
+		{name: "seconds", corsMaxAge: 30, want: "30"},
+		{name: "disabled", corsMaxAge: -1, want: "0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			httpConfig := &config.HttpConfig{CorsAllowedOrigins: "https://app.example.com", CorsMaxAge: tt.corsMaxAge}
+			recorder, _ := serve(NewOptionsFromConfig(httpConfig), preflight("https://app.example.com", "GET", ""))
+			values := recorder.Header().Values("Access-Control-Max-Age")
+
+			if len(values) != 1 || values[0] != tt.want {
+				t.Fatalf("Access-Control-Max-Age = %v, want %q", values, tt.want)
+			}
+		})
+	}
+}
+
+func TestMaxAgeNotSetInOptions(t *testing.T) {
+	options := testOptions
+	options.MaxAge = 0
+	recorder, _ := serve(options, preflight("https://app.example.com", "GET", ""))
+
+	if values := recorder.Header().Values("Access-Control-Max-Age"); len(values) != 0 {
+		t.Fatalf("Access-Control-Max-Age = %v, want no header", values)
+	}
+}
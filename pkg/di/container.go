@@ -2,9 +2,14 @@ package di
 
 import (
 	"github.com/exgamer/gosdk-core/pkg/di"
+	"github.com/exgamer/gosdk-http-core/pkg/apikey"
 	"github.com/exgamer/gosdk-http-core/pkg/config"
+	"github.com/exgamer/gosdk-http-core/pkg/health"
+	"github.com/exgamer/gosdk-http-core/pkg/jwt"
 	"github.com/exgamer/gosdk-http-core/pkg/metrics"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 // GetRouter возвращает HTTP router.
@@ -39,3 +44,58 @@ func GetMetricsCollector(c *di.Container) (*metrics.Collector, error) {
 
 	return m, nil
 }
+
+// GetHealthRegistry возвращает реестр health checks.
+func GetHealthRegistry(c *di.Container) (*health.Registry, error) {
+	h, err := di.Resolve[*health.Registry](c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// GetMetricsRegistry возвращает registry прометея kernel.
+func GetMetricsRegistry(c *di.Container) (*prometheus.Registry, error) {
+	r, err := di.Resolve[*prometheus.Registry](c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// GetTracerProvider возвращает TracerProvider OpenTelemetry (если включен трейсинг).
+func GetTracerProvider(c *di.Container) (*sdktrace.TracerProvider, error) {
+	t, err := di.Resolve[*sdktrace.TracerProvider](c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// GetJwtVerifier возвращает проверку JWT (если заданы JWT_* ключи).
+func GetJwtVerifier(c *di.Container) (*jwt.Verifier, error) {
+	v, err := di.Resolve[*jwt.Verifier](c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// GetApiKeyAuthenticator возвращает проверку API ключей (если заданы API_KEYS или зарегистрирована сервисом).
+func GetApiKeyAuthenticator(c *di.Container) (*apikey.Authenticator, error) {
+	a, err := di.Resolve[*apikey.Authenticator](c)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
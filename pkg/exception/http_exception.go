@@ -13,6 +13,8 @@ type HttpException struct {
 	Context       map[string]any
 	Code          int
 	TrackInSentry bool
+	// ErrorType тип ошибки в ответе, пустой - по Code
+	ErrorType string
 }
 
 func (e *HttpException) Error() string {
@@ -26,6 +28,10 @@ func (e *HttpException) Error() string {
 func (e *HttpException) Unwrap() error { return e.Err }
 
 func (e *HttpException) GetErrorType() string {
+	if e.ErrorType != "" {
+		return e.ErrorType
+	}
+
 	return constants.GetErrorTypeByStatusCode(e.Code)
 }
 
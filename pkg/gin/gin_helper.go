@@ -7,6 +7,7 @@ import (
 	"github.com/exgamer/gosdk-core/pkg/logger"
 	"github.com/exgamer/gosdk-http-core/pkg/config"
 	"github.com/exgamer/gosdk-http-core/pkg/constants"
+	"github.com/exgamer/gosdk-http-core/pkg/cors"
 	"github.com/getsentry/sentry-go"
 	sentrygin "github.com/getsentry/sentry-go/gin"
 	"github.com/gin-gonic/gin"
@@ -14,8 +15,11 @@ import (
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	timeout "github.com/vearne/gin-timeout"
-	ginprometheus "github.com/zsais/go-gin-prometheus"
+	"go.opentelemetry.io/otel/trace"
 	"net/http"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -42,8 +46,12 @@ func InitRouter(baseConfig *baseConfig.BaseConfig, httpConfig *config.HttpConfig
 		c.JSON(http.StatusNotFound, gin.H{"code": "PAGE_NOT_FOUND", "message": "404 page not found"})
 	})
 	router.HandleMethodNotAllowed = true
-	p := ginprometheus.NewPrometheus("ginHelpers")
-	p.Use(router)
+
+	// CORS первым: preflight отвечается до остальных middleware и не доходит до хендлеров
+	if httpConfig.IsCorsEnabled() {
+		router.Use(cors.Middleware(cors.NewOptionsFromConfig(httpConfig)))
+	}
+
 	router.Use(sentrygin.New(sentrygin.Options{}))
 	//router.Use(gin.Logger())
 	if httpConfig.HandlerTimeout > 0 {
@@ -68,7 +76,17 @@ func ErrorHandler(c *gin.Context, err any) {
 	c.JSON(http.StatusInternalServerError, gin.H{"message": goErr.Error(), "details": details, "success": false, "service_code": 0})
 }
 
+// GetInstanceHttpInfo Данные запроса из заголовков. Некорректные заголовки идентификации пропускаются,
+// чтобы получить ошибку - ParseHttpInfo
 func GetInstanceHttpInfo(c *gin.Context) *config.HttpInfo {
+	httpInfo, _ := ParseHttpInfo(c)
+
+	return httpInfo
+}
+
+// ParseHttpInfo Данные запроса из заголовков. HttpInfo возвращается всегда, ошибка *InvalidHeadersError -
+// если заголовки идентификации (User-Id, Company-Ids, ...) не удалось разобрать
+func ParseHttpInfo(c *gin.Context) (*config.HttpInfo, error) {
 	httpInfo := &config.HttpInfo{}
 	httpInfo.RequestId = c.GetHeader(constants.RequestIdHeaderName)
 	// если request id не пришел с заголовком, генерим его, чтобы прокидывать дальше при http запросах
@@ -84,14 +102,60 @@ func GetInstanceHttpInfo(c *gin.Context) *config.HttpInfo {
 	}
 
 	httpInfo.CacheControl = c.GetHeader(constants.CacheControlHeaderName)
+	httpInfo.AuthToken = GetBearerToken(c.GetHeader(constants.AuthorizationHeaderName))
+	httpInfo.AppsflyerId = c.GetHeader(constants.AppsflyerHeaderName)
+	httpInfo.Iin = c.GetHeader(constants.IinHeaderName)
+
+	invalidHeaders := &InvalidHeadersError{Details: make(map[string]any)}
+	httpInfo.CityId = parseIntHeader(c, constants.CityHeaderName, invalidHeaders)
+	httpInfo.UserId = parseIntHeader(c, constants.UserHeaderName, invalidHeaders)
+	httpInfo.CurrentCompanyId = parseIntHeader(c, constants.CurrentCompanyIdHeaderName, invalidHeaders)
+	httpInfo.CompanyId = parseIntHeader(c, constants.CompanyIdHeaderName, invalidHeaders)
+	httpInfo.CompanyIds = parseIntSliceHeader(c, constants.CompanyIdsHeaderName, invalidHeaders)
+
 	httpInfo.RequestUrl = c.Request.URL.Path
 	httpInfo.RequestMethod = c.Request.Method
 	httpInfo.RequestScheme = c.Request.URL.Scheme
 	httpInfo.RequestHost = c.Request.Host
+	httpInfo.Headers = c.Request.Header
 
-	return httpInfo
+	// трейс стартует TracingMiddleware раньше, поэтому спан уже в context запроса
+	if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsValid() {
+		httpInfo.TraceId = spanContext.TraceID().String()
+		httpInfo.SpanId = spanContext.SpanID().String()
+	}
+
+	// данные клиентского сертификата берем только если он прошел проверку (mTLS)
+	if c.Request.TLS != nil {
+		httpInfo.RequestScheme = "https"
+
+		if len(c.Request.TLS.VerifiedChains) > 0 && len(c.Request.TLS.VerifiedChains[0]) > 0 {
+			clientCert := c.Request.TLS.VerifiedChains[0][0]
+			httpInfo.ClientCertSubject = clientCert.Subject.String()
+			httpInfo.ClientCertCommonName = clientCert.Subject.CommonName
+		}
+	}
+
+	if len(invalidHeaders.Details) > 0 {
+		return httpInfo, invalidHeaders
+	}
+
+	return httpInfo, nil
 }
 
+// UnknownRoutePath значение шаблона роута для запросов без роута (404, 405)
+const UnknownRoutePath = "__unknown__"
+
+// GetRoutePath шаблон роута запроса для метрик и ключей лимитов, UnknownRoutePath - если роут не найден
+func GetRoutePath(c *gin.Context) string {
+	if path := c.FullPath(); path != "" {
+		return path
+	}
+
+	return UnknownRoutePath
+}
+
+// GetHttpInfoFromContext HttpInfo из context запроса
 func GetHttpInfoFromContext(ctx context.Context) *config.HttpInfo {
 	if v := ctx.Value(constants.HttpInfoKey); v != nil {
 		if hi, ok := v.(*config.HttpInfo); ok {
@@ -100,3 +164,121 @@ func GetHttpInfoFromContext(ctx context.Context) *config.HttpInfo {
 	}
 	return nil
 }
+
+// GetHttpInfoValue типизированное значение HttpInfo из context, добавленное экстрактором RequestInfoMiddleware
+func GetHttpInfoValue[T any](ctx context.Context, key string) (T, bool) {
+	return config.GetHttpInfoValue[T](GetHttpInfoFromContext(ctx), key)
+}
+
+// HttpInfoExtractor дополняет HttpInfo данными запроса (обычно доменными заголовками через httpInfo.SetValue).
+// Ошибка отклоняет запрос с 400, *InvalidHeadersError попадает в details ответа
+type HttpInfoExtractor func(c *gin.Context, httpInfo *config.HttpInfo) error
+
+// StringHeaderExtractor экстрактор строкового заголовка, значение доступно по имени заголовка
+func StringHeaderExtractor(name string) HttpInfoExtractor {
+	return func(c *gin.Context, httpInfo *config.HttpInfo) error {
+		if value := c.GetHeader(name); value != "" {
+			httpInfo.SetValue(name, value)
+		}
+
+		return nil
+	}
+}
+
+// IntHeaderExtractor экстрактор числового заголовка, значение (int) доступно по имени заголовка
+func IntHeaderExtractor(name string) HttpInfoExtractor {
+	return func(c *gin.Context, httpInfo *config.HttpInfo) error {
+		if c.GetHeader(name) == "" {
+			return nil
+		}
+
+		invalidHeaders := &InvalidHeadersError{Details: make(map[string]any)}
+		value := parseIntHeader(c, name, invalidHeaders)
+
+		if len(invalidHeaders.Details) > 0 {
+			return invalidHeaders
+		}
+
+		httpInfo.SetValue(name, value)
+
+		return nil
+	}
+}
+
+// InvalidHeadersError заголовки запроса, которые не удалось разобрать. Details - заголовок => описание ошибки
+type InvalidHeadersError struct {
+	Details map[string]any
+}
+
+func (e *InvalidHeadersError) Error() string {
+	names := make([]string, 0, len(e.Details))
+
+	for name := range e.Details {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return "invalid request headers: " + strings.Join(names, ", ")
+}
+
+// GetBearerToken токен из значения заголовка Authorization вида "Bearer <token>"
+func GetBearerToken(authorization string) string {
+	scheme, token, found := strings.Cut(strings.TrimSpace(authorization), " ")
+
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+
+	return strings.TrimSpace(token)
+}
+
+func parseIntHeader(c *gin.Context, name string, invalidHeaders *InvalidHeadersError) int {
+	value := strings.TrimSpace(c.GetHeader(name))
+
+	if value == "" {
+		return 0
+	}
+
+	parsed, err := strconv.Atoi(value)
+
+	if err != nil {
+		invalidHeaders.Details[name] = "must be an integer"
+
+		return 0
+	}
+
+	return parsed
+}
+
+// parseIntSliceHeader список чисел через запятую, например "1,2,3"
+func parseIntSliceHeader(c *gin.Context, name string, invalidHeaders *InvalidHeadersError) []int {
+	value := strings.Trim(strings.TrimSpace(c.GetHeader(name)), "[]")
+
+	if value == "" {
+		return nil
+	}
+
+	parts := strings.Split(value, ",")
+	parsed := make([]int, 0, len(parts))
+
+	for _, part := range parts {
+		part = strings.TrimSpace(part)
+
+		if part == "" {
+			continue
+		}
+
+		item, err := strconv.Atoi(part)
+
+		if err != nil {
+			invalidHeaders.Details[name] = "must be a comma separated list of integers"
+
+			return nil
+		}
+
+		parsed = append(parsed, item)
+	}
+
+	return parsed
+}
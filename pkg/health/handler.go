@@ -0,0 +1,42 @@
+package health
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+const (
+	LivePath  = "/live"
+	ReadyPath = "/ready"
+)
+
+// LiveHandler хендлер для liveness probe
+func LiveHandler(registry *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeReport(c, registry.Liveness(c.Request.Context()))
+	}
+}
+
+// ReadyHandler хендлер для readiness probe
+func ReadyHandler(registry *Registry) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		writeReport(c, registry.Readiness(c.Request.Context()))
+	}
+}
+
+// RegisterRoutes регистрирует /live и /ready в роутере
+func RegisterRoutes(router gin.IRoutes, registry *Registry) {
+	router.GET(LivePath, LiveHandler(registry))
+	router.GET(ReadyPath, ReadyHandler(registry))
+}
+
+func writeReport(c *gin.Context, report *Report) {
+	status := http.StatusOK
+
+	if !report.IsHealthy() {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
@@ -0,0 +1,88 @@
+package health
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+func TestRoutes(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		shuttingDown bool
+		wantStatus   int
+		wantReport   string
+	}{
+		{name: "live", path: LivePath, wantStatus: http.StatusOK, wantReport: StatusOk},
+		{name: "ready degraded", path: ReadyPath, wantStatus: http.StatusOK, wantReport: StatusDegraded},
+		{name: "ready shutting down", path: ReadyPath, shuttingDown: true, wantStatus: http.StatusServiceUnavailable, wantReport: StatusShuttingDown},
+		{name: "live shutting down", path: LivePath, shuttingDown: true, wantStatus: http.StatusOK, wantReport: StatusOk},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+
+			if err := registry.Register(Check{Name: "cache", Check: failCheck}); err != nil {
+				t.Fatal(err)
+			}
+
+			if tt.shuttingDown {
+				registry.SetShuttingDown()
+			}
+
+			router := gin.New()
+			RegisterRoutes(router, registry)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if got := recorder.Header().Get("Cache-Control"); got != "no-store" {
+				t.Fatalf("Cache-Control = %q, want no-store", got)
+			}
+
+			var report Report
+
+			if err := json.Unmarshal(recorder.Body.Bytes(), &report); err != nil {
+				t.Fatal(err)
+			}
+
+			if report.Status != tt.wantReport {
+				t.Fatalf("report status = %s, want %s", report.Status, tt.wantReport)
+			}
+		})
+	}
+}
+
+func TestReadyCriticalFail(t *testing.T) {
+	registry := NewRegistry()
+
+	if err := registry.Register(Check{Name: "db", Critical: true, Check: failCheck}); err != nil {
+		t.Fatal(err)
+	}
+
+	router := gin.New()
+	router.GET(ReadyPath, ReadyHandler(registry))
+
+	request := httptest.NewRequest(http.MethodGet, ReadyPath, nil)
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, request)
+
+	if recorder.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,233 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// DefaultCheckTimeout таймаут проверки, если он не указан
+const DefaultCheckTimeout = 3 * time.Second
+
+// Статусы проверок
+const (
+	StatusOk           = "ok"
+	StatusDegraded     = "degraded"
+	StatusFail         = "fail"
+	StatusShuttingDown = "shutting_down"
+)
+
+// CheckFunc функция проверки компонента, возвращает ошибку если компонент недоступен
+type CheckFunc func(ctx context.Context) error
+
+// Check описание проверки компонента (БД, кеш, внешний HTTP сервис и т.д.)
+type Check struct {
+	Name string
+	// Timeout таймаут проверки, по умолчанию DefaultCheckTimeout
+	Timeout time.Duration
+	// Critical если критичная проверка упала - сервис не готов принимать трафик,
+	// некритичная переводит статус в degraded
+	Critical bool
+	Check    CheckFunc
+}
+
+// CheckResult результат одной проверки
+type CheckResult struct {
+	Status     string `json:"status"`
+	Critical   bool   `json:"critical"`
+	DurationMs int64  `json:"duration_ms"`
+	Error      string `json:"error,omitempty"`
+}
+
+// Report результат всех проверок
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// IsHealthy можно ли отдавать на балансировщик успешный ответ
+func (r *Report) IsHealthy() bool {
+	return r.Status == StatusOk || r.Status == StatusDegraded
+}
+
+func NewRegistry() *Registry {
+	return &Registry{
+		liveness:  make([]Check, 0),
+		readiness: make([]Check, 0),
+	}
+}
+
+// Registry реестр проверок для /live и /ready
+type Registry struct {
+	mu           sync.RWMutex
+	liveness     []Check
+	readiness    []Check
+	shuttingDown atomic.Bool
+}
+
+// Register регистрирует проверку готовности (/ready)
+func (r *Registry) Register(check Check) error {
+	return r.add(&r.readiness, check)
+}
+
+// RegisterLiveness регистрирует проверку живости (/live).
+// Сюда стоит добавлять только проверки самого процесса, а не внешних зависимостей
+func (r *Registry) RegisterLiveness(check Check) error {
+	return r.add(&r.liveness, check)
+}
+
+// SetShuttingDown переводит readiness в fail, чтобы балансировщик перестал слать трафик
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// IsShuttingDown началась ли остановка сервиса
+func (r *Registry) IsShuttingDown() bool {
+	return r.shuttingDown.Load()
+}
+
+// Liveness выполняет проверки живости
+func (r *Registry) Liveness(ctx context.Context) *Report {
+	r.mu.RLock()
+	checks := append([]Check(nil), r.liveness...)
+	r.mu.RUnlock()
+
+	return run(ctx, checks)
+}
+
+// Readiness выполняет проверки готовности
+func (r *Registry) Readiness(ctx context.Context) *Report {
+	if r.IsShuttingDown() {
+		return &Report{
+			Status: StatusShuttingDown,
+			Checks: map[string]CheckResult{},
+		}
+	}
+
+	r.mu.RLock()
+	checks := append([]Check(nil), r.readiness...)
+	r.mu.RUnlock()
+
+	return run(ctx, checks)
+}
+
+func (r *Registry) add(checks *[]Check, check Check) error {
+	if check.Name == "" {
+		return errors.New("health check name is empty")
+	}
+
+	if check.Check == nil {
+		return fmt.Errorf("health check %s has no check func", check.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, c := range *checks {
+		if c.Name == check.Name {
+			return fmt.Errorf("health check already registered: %s", check.Name)
+		}
+	}
+
+	*checks = append(*checks, check)
+
+	return nil
+}
+
+// run выполняет проверки параллельно, каждую со своим таймаутом
+func run(ctx context.Context, checks []Check) *Report {
+	report := &Report{
+		Status: StatusOk,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for _, check := range checks {
+		wg.Add(1)
+
+		go func(check Check) {
+			defer wg.Done()
+
+			result := runOne(ctx, check)
+
+			mu.Lock()
+			report.Checks[check.Name] = result
+			mu.Unlock()
+		}(check)
+	}
+
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == StatusOk {
+			continue
+		}
+
+		if result.Critical {
+			report.Status = StatusFail
+
+			break
+		}
+
+		report.Status = StatusDegraded
+	}
+
+	return report
+}
+
+func runOne(ctx context.Context, check Check) (result CheckResult) {
+	timeout := check.Timeout
+
+	if timeout <= 0 {
+		timeout = DefaultCheckTimeout
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	result.Critical = check.Critical
+
+	defer func() {
+		result.DurationMs = time.Since(start).Milliseconds()
+	}()
+
+	// канал с буфером, чтобы зависшая проверка не блокировала горутину после таймаута
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer func() {
+			if p := recover(); p != nil {
+				errCh <- fmt.Errorf("panic: %v", p)
+			}
+		}()
+
+		errCh <- check.Check(ctx)
+	}()
+
+	var err error
+
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = fmt.Errorf("timeout after %s", timeout)
+	}
+
+	if err != nil {
+		result.Status = StatusFail
+		result.Error = err.Error()
+
+		return result
+	}
+
+	result.Status = StatusOk
+
+	return result
+}
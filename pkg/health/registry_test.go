@@ -0,0 +1,157 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func okCheck(context.Context) error {
+	return nil
+}
+
+func failCheck(context.Context) error {
+	return errors.New("connection refused")
+}
+
+func TestReadiness(t *testing.T) {
+	tests := []struct {
+		name       string
+		checks     []Check
+		wantStatus string
+		wantErrors map[string]string
+	}{
+		{name: "without checks", wantStatus: StatusOk},
+		{name: "ok", checks: []Check{{Name: "db", Critical: true, Check: okCheck}}, wantStatus: StatusOk},
+		{
+			name:       "non critical fail",
+			checks:     []Check{{Name: "db", Critical: true, Check: okCheck}, {Name: "cache", Check: failCheck}},
+			wantStatus: StatusDegraded,
+			wantErrors: map[string]string{"cache": "connection refused"},
+		},
+		{
+			name:       "critical fail",
+			checks:     []Check{{Name: "db", Critical: true, Check: failCheck}, {Name: "cache", Check: failCheck}},
+			wantStatus: StatusFail,
+			wantErrors: map[string]string{"db": "connection refused", "cache": "connection refused"},
+		},
+		{
+			name: "timeout",
+			checks: []Check{{Name: "db", Critical: true, Timeout: 10 * time.Millisecond, Check: func(ctx context.Context) error {
+				<-ctx.Done()
+				time.Sleep(10 * time.Millisecond)
+
+				return nil
+			}}},
+			wantStatus: StatusFail,
+			wantErrors: map[string]string{"db": "timeout after 10ms"},
+		},
+		{
+			name:       "panic",
+			checks:     []Check{{Name: "db", Critical: true, Check: func(context.Context) error { panic("boom") }}},
+			wantStatus: StatusFail,
+			wantErrors: map[string]string{"db": "panic: boom"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+
+			for _, check := range tt.checks {
+				if err := registry.Register(check); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			report := registry.Readiness(context.Background())
+
+			if report.Status != tt.wantStatus {
+				t.Fatalf("status = %s, want %s", report.Status, tt.wantStatus)
+			}
+
+			if len(report.Checks) != len(tt.checks) {
+				t.Fatalf("checks = %v, want %d results", report.Checks, len(tt.checks))
+			}
+
+			for _, check := range tt.checks {
+				result := report.Checks[check.Name]
+
+				if result.Error != tt.wantErrors[check.Name] {
+					t.Fatalf("%s error = %q, want %q", check.Name, result.Error, tt.wantErrors[check.Name])
+				}
+
+				if result.Critical != check.Critical {
+					t.Fatalf("%s critical = %v, want %v", check.Name, result.Critical, check.Critical)
+				}
+			}
+		})
+	}
+}
+
+func TestReadinessShuttingDown(t *testing.T) {
+	registry := NewRegistry()
+
+	if err := registry.Register(Check{Name: "db", Check: okCheck}); err != nil {
+		t.Fatal(err)
+	}
+
+	registry.SetShuttingDown()
+
+	if report := registry.Readiness(context.Background()); report.Status != StatusShuttingDown || report.IsHealthy() {
+		t.Fatalf("status = %s, want %s", report.Status, StatusShuttingDown)
+	}
+
+	// liveness при остановке не меняется, иначе процесс перезапустят до завершения запросов
+	if report := registry.Liveness(context.Background()); report.Status != StatusOk {
+		t.Fatalf("liveness status = %s, want %s", report.Status, StatusOk)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	tests := []struct {
+		name    string
+		check   Check
+		wantErr bool
+	}{
+		{name: "valid", check: Check{Name: "cache", Check: okCheck}},
+		{name: "without name", check: Check{Check: okCheck}, wantErr: true},
+		{name: "without func", check: Check{Name: "cache"}, wantErr: true},
+		{name: "duplicate", check: Check{Name: "db", Check: okCheck}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry()
+
+			if err := registry.Register(Check{Name: "db", Check: okCheck}); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := registry.Register(tt.check); (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLivenessAndReadinessAreSeparate(t *testing.T) {
+	registry := NewRegistry()
+
+	if err := registry.RegisterLiveness(Check{Name: "db", Critical: true, Check: failCheck}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := registry.Register(Check{Name: "db", Critical: true, Check: okCheck}); err != nil {
+		t.Fatal(err)
+	}
+
+	if report := registry.Liveness(context.Background()); report.Status != StatusFail {
+		t.Fatalf("liveness status = %s, want %s", report.Status, StatusFail)
+	}
+
+	if report := registry.Readiness(context.Background()); report.Status != StatusOk {
+		t.Fatalf("readiness status = %s, want %s", report.Status, StatusOk)
+	}
+}
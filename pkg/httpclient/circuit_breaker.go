@@ -0,0 +1,209 @@
+package httpclient
+
+import (
+	"errors"
+	"github.com/exgamer/gosdk-http-core/pkg/metrics"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen запрос не выполнен, потому что circuit breaker хоста открыт
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// CircuitState состояние circuit breaker, значение экспортируется в метрику
+type CircuitState int
+
+const (
+	CircuitClosed   CircuitState = 0
+	CircuitHalfOpen CircuitState = 1
+	CircuitOpen     CircuitState = 2
+)
+
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half_open"
+	default:
+		return "closed"
+	}
+}
+
+const (
+	DefaultCircuitFailureThreshold = 5
+	DefaultCircuitOpenTimeout      = 30 * time.Second
+)
+
+// CircuitBreakerSettings настройки circuit breaker
+type CircuitBreakerSettings struct {
+	// FailureThreshold количество неуспешных запросов подряд, после которого breaker открывается
+	FailureThreshold int
+	// OpenTimeout сколько breaker открыт, прежде чем пропустить пробный запрос
+	OpenTimeout time.Duration
+	// HalfOpenMaxRequests количество одновременных пробных запросов в состоянии half-open
+	HalfOpenMaxRequests int
+}
+
+// NewCircuitBreakers создает набор circuit breaker по хостам, состояние которых экспортируется через collector (может быть nil)
+func NewCircuitBreakers(settings CircuitBreakerSettings, collector *metrics.Collector) *CircuitBreakers {
+	if settings.FailureThreshold <= 0 {
+		settings.FailureThreshold = DefaultCircuitFailureThreshold
+	}
+
+	if settings.OpenTimeout <= 0 {
+		settings.OpenTimeout = DefaultCircuitOpenTimeout
+	}
+
+	if settings.HalfOpenMaxRequests <= 0 {
+		settings.HalfOpenMaxRequests = 1
+	}
+
+	return &CircuitBreakers{
+		settings:  settings,
+		collector: collector,
+		breakers:  make(map[string]*CircuitBreaker),
+	}
+}
+
+// CircuitBreakers circuit breaker для каждого хоста
+type CircuitBreakers struct {
+	settings  CircuitBreakerSettings
+	collector *metrics.Collector
+
+	mu       sync.Mutex
+	breakers map[string]*CircuitBreaker
+}
+
+// Get возвращает circuit breaker хоста, создавая его при отсутствии
+func (b *CircuitBreakers) Get(host string) *CircuitBreaker {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if breaker, ok := b.breakers[host]; ok {
+		return breaker
+	}
+
+	breaker := &CircuitBreaker{host: host, settings: b.settings, collector: b.collector}
+	breaker.exportState()
+	b.breakers[host] = breaker
+
+	return breaker
+}
+
+// CircuitBreaker circuit breaker одного хоста
+type CircuitBreaker struct {
+	host      string
+	settings  CircuitBreakerSettings
+	collector *metrics.Collector
+
+	mu               sync.Mutex
+	state            CircuitState
+	failures         int
+	openedAt         time.Time
+	halfOpenInFlight int
+	// generation меняется при каждой смене состояния: результаты запросов, допущенных в прошлом состоянии, игнорируются
+	generation uint64
+}
+
+// State текущее состояние
+func (b *CircuitBreaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.state
+}
+
+// Allow можно ли выполнить запрос. После Allow нужно вызвать Success, Failure или Release с полученным generation
+func (b *CircuitBreaker) Allow() (generation uint64, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == CircuitOpen {
+		if time.Since(b.openedAt) < b.settings.OpenTimeout {
+			return b.generation, false
+		}
+
+		b.setState(CircuitHalfOpen)
+	}
+
+	if b.state == CircuitHalfOpen {
+		if b.halfOpenInFlight >= b.settings.HalfOpenMaxRequests {
+			return b.generation, false
+		}
+
+		b.halfOpenInFlight++
+	}
+
+	return b.generation, true
+}
+
+// Success успешный запрос закрывает breaker. Запрос, допущенный до смены состояния, ничего не меняет:
+// поздний ответ, отправленный до открытия breaker, не должен его закрыть
+func (b *CircuitBreaker) Success(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	b.release()
+	b.failures = 0
+	b.setState(CircuitClosed)
+}
+
+// Failure неуспешный запрос; в half-open сразу открывает breaker, в closed - по достижении FailureThreshold.
+// Запрос, допущенный до смены состояния, ничего не меняет
+func (b *CircuitBreaker) Failure(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+
+	b.release()
+	b.failures++
+
+	if b.state == CircuitHalfOpen || b.failures >= b.settings.FailureThreshold {
+		b.openedAt = time.Now()
+		b.setState(CircuitOpen)
+	}
+}
+
+// Release запрос не дошел до результата (например, отменен вызывающим), состояние не меняется
+func (b *CircuitBreaker) Release(generation uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation == b.generation {
+		b.release()
+	}
+}
+
+func (b *CircuitBreaker) release() {
+	if b.state == CircuitHalfOpen && b.halfOpenInFlight > 0 {
+		b.halfOpenInFlight--
+	}
+}
+
+func (b *CircuitBreaker) setState(state CircuitState) {
+	if b.state == state {
+		return
+	}
+
+	if state != CircuitHalfOpen {
+		b.halfOpenInFlight = 0
+	}
+
+	b.state = state
+	b.generation++
+	b.exportState()
+}
+
+func (b *CircuitBreaker) exportState() {
+	if b.collector != nil {
+		b.collector.SetCircuitBreakerState(b.host, float64(b.state))
+	}
+}
@@ -0,0 +1,121 @@
+package httpclient
+
+import (
+	"testing"
+	"time"
+)
+
+func newTestBreaker(openTimeout time.Duration) *CircuitBreaker {
+	return NewCircuitBreakers(CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: openTimeout}, nil).Get("example.com")
+}
+
+func fail(t *testing.T, breaker *CircuitBreaker) {
+	t.Helper()
+
+	generation, ok := breaker.Allow()
+
+	if !ok {
+		t.Fatal("Allow() = false, want true")
+	}
+
+	breaker.Failure(generation)
+}
+
+func TestCircuitBreakerOpensAfterThreshold(t *testing.T) {
+	breaker := newTestBreaker(time.Hour)
+
+	fail(t, breaker)
+
+	if state := breaker.State(); state != CircuitClosed {
+		t.Fatalf("state after 1 failure = %s, want closed", state)
+	}
+
+	fail(t, breaker)
+
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("state after 2 failures = %s, want open", state)
+	}
+
+	if _, ok := breaker.Allow(); ok {
+		t.Fatal("open breaker allowed request")
+	}
+}
+
+func TestCircuitBreakerSuccessResetsFailures(t *testing.T) {
+	breaker := newTestBreaker(time.Hour)
+
+	fail(t, breaker)
+
+	generation, _ := breaker.Allow()
+	breaker.Success(generation)
+
+	fail(t, breaker)
+
+	if state := breaker.State(); state != CircuitClosed {
+		t.Fatalf("state = %s, want closed: failures must be consecutive", state)
+	}
+}
+
+func TestCircuitBreakerHalfOpen(t *testing.T) {
+	tests := []struct {
+		name    string
+		success bool
+		want    CircuitState
+	}{
+		{name: "probe succeeds", success: true, want: CircuitClosed},
+		{name: "probe fails", success: false, want: CircuitOpen},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			breaker := newTestBreaker(time.Millisecond)
+
+			fail(t, breaker)
+			fail(t, breaker)
+			time.Sleep(2 * time.Millisecond)
+
+			generation, ok := breaker.Allow()
+
+			if !ok || breaker.State() != CircuitHalfOpen {
+				t.Fatalf("Allow() = %v, state = %s, want probe in half_open", ok, breaker.State())
+			}
+
+			if _, ok := breaker.Allow(); ok {
+				t.Fatal("half_open breaker allowed more than HalfOpenMaxRequests probes")
+			}
+
+			if tt.success {
+				breaker.Success(generation)
+			} else {
+				breaker.Failure(generation)
+			}
+
+			if state := breaker.State(); state != tt.want {
+				t.Fatalf("state = %s, want %s", state, tt.want)
+			}
+		})
+	}
+}
+
+func TestCircuitBreakerIgnoresOutcomeFromPreviousState(t *testing.T) {
+	breaker := newTestBreaker(time.Hour)
+
+	// запрос допущен, пока breaker закрыт, а ответ приходит после открытия
+	lateGeneration, _ := breaker.Allow()
+
+	fail(t, breaker)
+	fail(t, breaker)
+
+	breaker.Success(lateGeneration)
+
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("late success changed state to %s, want open", state)
+	}
+
+	breaker.Release(lateGeneration)
+	breaker.Failure(lateGeneration)
+
+	if state := breaker.State(); state != CircuitOpen {
+		t.Fatalf("state = %s, want open", state)
+	}
+}
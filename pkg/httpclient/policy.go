@@ -0,0 +1,266 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/exgamer/gosdk-http-core/pkg/exception"
+	"github.com/exgamer/gosdk-http-core/pkg/metrics"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	DefaultRetryMaxAttempts    = 3
+	DefaultRetryInitialBackoff = 100 * time.Millisecond
+	DefaultRetryMaxBackoff     = 2 * time.Second
+	DefaultRetryMaxRetryAfter  = 10 * time.Second
+	IdempotencyKeyHeaderName   = "Idempotency-Key"
+)
+
+// DefaultRetryableStatuses статусы, на которые запрос повторяется
+var DefaultRetryableStatuses = []int{
+	http.StatusTooManyRequests,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// RetryPolicy настройки повторов. Повторяются только идемпотентные методы
+// (GET, HEAD, OPTIONS, PUT, DELETE, TRACE) и запросы с заголовком Idempotency-Key
+type RetryPolicy struct {
+	// MaxAttempts общее количество попыток, включая первую. 0 и 1 - без повторов
+	MaxAttempts int
+	// InitialBackoff задержка перед первым повтором, дальше удваивается (со случайным разбросом) до MaxBackoff
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// MaxRetryAfter максимальный Retry-After, который готовы подождать. Если сервис просит больше - не повторяем
+	MaxRetryAfter time.Duration
+	// RetryableStatuses статусы ответа, на которые запрос повторяется
+	RetryableStatuses []int
+}
+
+// Policy политика отказоустойчивости RestClient
+type Policy struct {
+	Retry RetryPolicy
+	// CallTimeout таймаут одной попытки. Попытка также ограничена дедлайном входящего ctx
+	// (например, HANDLER_TIMEOUT запроса), повтор не начинается, если до дедлайна не дождаться задержки
+	CallTimeout time.Duration
+	// Breakers circuit breaker по хостам, nil - без circuit breaking
+	Breakers *CircuitBreakers
+}
+
+// NewPolicy политика с настройками по умолчанию: 3 попытки, backoff от 100ms до 2s,
+// circuit breaker открывается после 5 неуспешных запросов подряд на 30s
+func NewPolicy(collector *metrics.Collector) *Policy {
+	return &Policy{
+		Retry: RetryPolicy{
+			MaxAttempts:       DefaultRetryMaxAttempts,
+			InitialBackoff:    DefaultRetryInitialBackoff,
+			MaxBackoff:        DefaultRetryMaxBackoff,
+			MaxRetryAfter:     DefaultRetryMaxRetryAfter,
+			RetryableStatuses: DefaultRetryableStatuses,
+		},
+		Breakers: NewCircuitBreakers(CircuitBreakerSettings{}, collector),
+	}
+}
+
+// execute выполняет запрос по политике клиента. Тело ответа уже прочитано и закрыто
+func (c *RestClient) execute(ctx context.Context, request *http.Request) (*http.Response, []byte, error) {
+	policy := c.Policy
+
+	if policy == nil {
+		return c.attempt(ctx, request, 0)
+	}
+
+	maxAttempts := 1
+
+	if policy.Retry.MaxAttempts > 1 && isRetryableRequest(request) {
+		maxAttempts = policy.Retry.MaxAttempts
+	}
+
+	var breaker *CircuitBreaker
+
+	if policy.Breakers != nil {
+		breaker = policy.Breakers.Get(request.URL.Host)
+	}
+
+	for attempt := 1; ; attempt++ {
+		var generation uint64
+
+		if breaker != nil {
+			var allowed bool
+
+			if generation, allowed = breaker.Allow(); !allowed {
+				return nil, nil, exception.NewUntrackableHttpException(
+					http.StatusServiceUnavailable,
+					fmt.Errorf("%w: %s", ErrCircuitOpen, request.URL.Host),
+					map[string]any{"host": request.URL.Host},
+				)
+			}
+		}
+
+		response, body, err := c.attempt(ctx, request, policy.CallTimeout)
+
+		if breaker != nil {
+			switch {
+			case ctx.Err() != nil:
+				// запрос отменил вызывающий, это не говорит о состоянии сервиса
+				breaker.Release(generation)
+			case err != nil || response.StatusCode >= http.StatusInternalServerError:
+				breaker.Failure(generation)
+			default:
+				breaker.Success(generation)
+			}
+		}
+
+		if attempt >= maxAttempts || !policy.Retry.shouldRetry(ctx, response, err) {
+			return response, body, err
+		}
+
+		wait, ok := policy.Retry.backoff(attempt, response)
+
+		if !ok {
+			return response, body, err
+		}
+
+		if deadline, hasDeadline := ctx.Deadline(); hasDeadline && time.Until(deadline) < wait {
+			return response, body, err
+		}
+
+		if request.GetBody != nil {
+			requestBody, bodyErr := request.GetBody()
+
+			if bodyErr != nil {
+				return response, body, err
+			}
+
+			request.Body = requestBody
+		}
+
+		timer := time.NewTimer(wait)
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+
+			return response, body, err
+		case <-timer.C:
+		}
+	}
+}
+
+// attempt одна попытка запроса с таймаутом timeout (0 - без своего таймаута)
+func (c *RestClient) attempt(ctx context.Context, request *http.Request, timeout time.Duration) (*http.Response, []byte, error) {
+	attemptCtx := ctx
+
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	response, err := c.HttpClient.Do(request.WithContext(attemptCtx))
+
+	if err != nil {
+		addDebugStatement(ctx, request, 0, time.Since(start))
+
+		return nil, nil, err
+	}
+
+	defer response.Body.Close()
+
+	body, err := io.ReadAll(response.Body)
+	addDebugStatement(ctx, request, response.StatusCode, time.Since(start))
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return response, body, nil
+}
+
+func (p RetryPolicy) shouldRetry(ctx context.Context, response *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+
+	if err != nil {
+		return !errors.Is(err, context.Canceled)
+	}
+
+	return slices.Contains(p.RetryableStatuses, response.StatusCode)
+}
+
+// backoff задержка перед повтором: Retry-After ответа, если он есть, иначе экспоненциальная с jitter.
+// false - Retry-After больше MaxRetryAfter, повторять не нужно
+func (p RetryPolicy) backoff(attempt int, response *http.Response) (time.Duration, bool) {
+	if response != nil {
+		if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			return retryAfter, p.MaxRetryAfter <= 0 || retryAfter <= p.MaxRetryAfter
+		}
+	}
+
+	backoff := p.InitialBackoff
+
+	for i := 1; i < attempt && (p.MaxBackoff <= 0 || backoff < p.MaxBackoff); i++ {
+		backoff *= 2
+	}
+
+	if p.MaxBackoff > 0 && backoff > p.MaxBackoff {
+		backoff = p.MaxBackoff
+	}
+
+	if backoff <= 0 {
+		return 0, true
+	}
+
+	// половина задержки фиксированная, половина случайная, чтобы клиенты не повторяли запросы одновременно
+	half := backoff / 2
+
+	return half + rand.N(backoff-half+1), true
+}
+
+// parseRetryAfter Retry-After в секундах или HTTP-дате
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if date, err := http.ParseTime(value); err == nil {
+		return max(time.Until(date), 0), true
+	}
+
+	return 0, false
+}
+
+func isRetryableRequest(request *http.Request) bool {
+	// тело, которое нельзя перечитать (произвольный io.Reader), повторно не отправить
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return false
+	}
+
+	if request.Header.Get(IdempotencyKeyHeaderName) != "" {
+		return true
+	}
+
+	switch request.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,255 @@
+package httpclient
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/exgamer/gosdk-http-core/pkg/exception"
+)
+
+// scriptedServer отвечает статусами statuses по очереди (последний повторяется) и запоминает тела запросов
+type scriptedServer struct {
+	*httptest.Server
+	mu       sync.Mutex
+	statuses []int
+	header   http.Header
+	bodies   []string
+}
+
+func newScriptedServer(t *testing.T, header http.Header, statuses ...int) *scriptedServer {
+	t.Helper()
+
+	server := &scriptedServer{statuses: statuses, header: header}
+	server.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		server.mu.Lock()
+		status := server.statuses[min(len(server.bodies), len(server.statuses)-1)]
+		server.bodies = append(server.bodies, string(body))
+		server.mu.Unlock()
+
+		for name, values := range server.header {
+			w.Header()[name] = values
+		}
+
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"success":true,"data":"ok"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return server
+}
+
+func (s *scriptedServer) attempts() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string(nil), s.bodies...)
+}
+
+func newTestPolicy() *Policy {
+	return &Policy{Retry: RetryPolicy{
+		MaxAttempts:       3,
+		InitialBackoff:    time.Millisecond,
+		MaxBackoff:        time.Millisecond,
+		MaxRetryAfter:     time.Second,
+		RetryableStatuses: DefaultRetryableStatuses,
+	}}
+}
+
+func TestRestClientRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		request      *Request
+		statuses     []int
+		header       http.Header
+		wantAttempts int
+		wantStatus   int
+	}{
+		{name: "get retried", request: &Request{Method: http.MethodGet}, statuses: []int{503, 200}, wantAttempts: 2, wantStatus: 200},
+		{name: "attempts exhausted", request: &Request{Method: http.MethodGet}, statuses: []int{503}, wantAttempts: 3, wantStatus: 503},
+		{name: "status is not retryable", request: &Request{Method: http.MethodGet}, statuses: []int{500, 200}, wantAttempts: 1, wantStatus: 500},
+		{name: "post is not retried", request: &Request{Method: http.MethodPost, Body: map[string]string{"id": "1"}}, statuses: []int{503, 200}, wantAttempts: 1, wantStatus: 503},
+		{
+			name:         "post with idempotency key",
+			request:      &Request{Method: http.MethodPost, Body: map[string]string{"id": "1"}, Headers: map[string]string{IdempotencyKeyHeaderName: "key-1"}},
+			statuses:     []int{503, 200},
+			wantAttempts: 2,
+			wantStatus:   200,
+		},
+		{
+			// тело из произвольного io.Reader нельзя перечитать для повтора
+			name:         "body cannot be replayed",
+			request:      &Request{Method: http.MethodPut, Body: io.MultiReader(strings.NewReader(`{"id":"1"}`))},
+			statuses:     []int{503, 200},
+			wantAttempts: 1,
+			wantStatus:   503,
+		},
+		{name: "retry after", request: &Request{Method: http.MethodGet}, statuses: []int{429, 200}, header: http.Header{"Retry-After": {"0"}}, wantAttempts: 2, wantStatus: 200},
+		{name: "retry after too long", request: &Request{Method: http.MethodGet}, statuses: []int{429, 200}, header: http.Header{"Retry-After": {"60"}}, wantAttempts: 1, wantStatus: 429},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newScriptedServer(t, tt.header, tt.statuses...)
+			client := NewRestClient(server.URL, time.Second)
+			client.Policy = newTestPolicy()
+
+			response, err := Do[string](context.Background(), client, tt.request)
+
+			if response == nil {
+				t.Fatalf("response = nil, err = %v", err)
+			}
+
+			if response.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", response.StatusCode, tt.wantStatus)
+			}
+
+			var httpException *exception.HttpException
+
+			if (tt.wantStatus != http.StatusOK) != errors.As(err, &httpException) {
+				t.Fatalf("err = %v, want HttpException only for error status", err)
+			}
+
+			attempts := server.attempts()
+
+			if len(attempts) != tt.wantAttempts {
+				t.Fatalf("attempts = %d, want %d", len(attempts), tt.wantAttempts)
+			}
+
+			// повтор отправляет то же тело, а не пустое
+			for i, body := range attempts {
+				if body != attempts[0] {
+					t.Fatalf("attempt %d body = %q, want %q", i+1, body, attempts[0])
+				}
+			}
+		})
+	}
+}
+
+func TestRestClientRetryStopsAtDeadline(t *testing.T) {
+	server := newScriptedServer(t, http.Header{"Retry-After": {"1"}}, 503, 200)
+	client := NewRestClient(server.URL, time.Second)
+	client.Policy = newTestPolicy()
+
+	// до дедлайна не дождаться Retry-After - повтор не начинается
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+
+	response, err := Get[string](ctx, client, "/orders", nil)
+
+	if err == nil || response.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, err = %v, want 503 error", response.StatusCode, err)
+	}
+
+	if attempts := len(server.attempts()); attempts != 1 {
+		t.Fatalf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestRestClientRetryWithBreaker(t *testing.T) {
+	server := newScriptedServer(t, nil, 503)
+	client := NewRestClient(server.URL, time.Second)
+	client.Policy = newTestPolicy()
+	client.Policy.Breakers = NewCircuitBreakers(CircuitBreakerSettings{FailureThreshold: 2, OpenTimeout: time.Hour}, nil)
+
+	_, err := Get[string](context.Background(), client, "/orders", nil)
+
+	// вторая неуспешная попытка открывает breaker, третья не отправляется
+	if !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("err = %v, want %v", err, ErrCircuitOpen)
+	}
+
+	if attempts := len(server.attempts()); attempts != 2 {
+		t.Fatalf("attempts = %d, want 2", attempts)
+	}
+}
+
+func TestIsRetryableRequest(t *testing.T) {
+	tests := []struct {
+		method         string
+		idempotencyKey string
+		want           bool
+	}{
+		{method: http.MethodGet, want: true},
+		{method: http.MethodHead, want: true},
+		{method: http.MethodPut, want: true},
+		{method: http.MethodDelete, want: true},
+		{method: http.MethodPost},
+		{method: http.MethodPatch},
+		{method: http.MethodPost, idempotencyKey: "key-1", want: true},
+		{method: http.MethodPatch, idempotencyKey: "key-1", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.idempotencyKey, func(t *testing.T) {
+			request := httptest.NewRequest(tt.method, "http://orders/v1", nil)
+
+			if tt.idempotencyKey != "" {
+				request.Header.Set(IdempotencyKeyHeaderName, tt.idempotencyKey)
+			}
+
+			if got := isRetryableRequest(request); got != tt.want {
+				t.Fatalf("isRetryableRequest = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, MaxRetryAfter: 10 * time.Second}
+
+	tests := []struct {
+		name    string
+		attempt int
+		min     time.Duration
+		max     time.Duration
+	}{
+		{name: "first retry", attempt: 1, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		{name: "doubled", attempt: 2, min: 100 * time.Millisecond, max: 200 * time.Millisecond},
+		{name: "max backoff", attempt: 5, min: 150 * time.Millisecond, max: 300 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for range 100 {
+				backoff, ok := policy.backoff(tt.attempt, nil)
+
+				if !ok || backoff < tt.min || backoff > tt.max {
+					t.Fatalf("backoff = %s, %v, want between %s and %s", backoff, ok, tt.min, tt.max)
+				}
+			}
+		})
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		value  string
+		want   time.Duration
+		wantOk bool
+	}{
+		{value: ""},
+		{value: "5", want: 5 * time.Second, wantOk: true},
+		{value: "-1"},
+		{value: "soon"},
+		{value: "Wed, 21 Oct 2015 07:28:00 GMT", want: 0, wantOk: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+
+			if got != tt.want || ok != tt.wantOk {
+				t.Fatalf("parseRetryAfter = %s, %v, want %s, %v", got, ok, tt.want, tt.wantOk)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+package httpclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/exgamer/gosdk-core/pkg/debug"
+	"github.com/exgamer/gosdk-http-core/pkg/exception"
+	"github.com/exgamer/gosdk-http-core/pkg/structures"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DebugCategory категория DebugCollector, в которую пишутся исходящие запросы
+const DebugCategory = "http"
+
+// DebugStatement исходящий запрос в DebugCollector
+type DebugStatement struct {
+	Method string `json:"method"`
+	Url    string `json:"url"`
+	Status int    `json:"status"`
+}
+
+// Request описание исходящего REST запроса
+type Request struct {
+	Method string
+	// Url абсолютный url или путь относительно BaseUrl клиента
+	Url     string
+	Query   url.Values
+	Headers map[string]string
+	// Body тело запроса: []byte и io.Reader отправляются как есть, остальное сериализуется в JSON
+	Body any
+}
+
+// NewRestClient создает REST клиент с PropagationTransport
+func NewRestClient(baseUrl string, timeout time.Duration, forwardedHeaders ...string) *RestClient {
+	return &RestClient{
+		BaseUrl:    strings.TrimRight(baseUrl, "/"),
+		HttpClient: NewClient(timeout, forwardedHeaders...),
+	}
+}
+
+// RestClient клиент для запросов к сервисам, отвечающим в стандартном конверте {success, data}
+type RestClient struct {
+	BaseUrl    string
+	HttpClient *http.Client
+	// Policy повторы, таймауты попыток и circuit breaking, nil - одна попытка
+	Policy *Policy
+}
+
+// Get GET запрос, ответ декодируется в E
+func Get[E any](ctx context.Context, client *RestClient, path string, query url.Values) (*structures.HttpResponse[E], error) {
+	return Do[E](ctx, client, &Request{Method: http.MethodGet, Url: path, Query: query})
+}
+
+// Post POST запрос с JSON телом
+func Post[E any](ctx context.Context, client *RestClient, path string, body any) (*structures.HttpResponse[E], error) {
+	return Do[E](ctx, client, &Request{Method: http.MethodPost, Url: path, Body: body})
+}
+
+// Put PUT запрос с JSON телом
+func Put[E any](ctx context.Context, client *RestClient, path string, body any) (*structures.HttpResponse[E], error) {
+	return Do[E](ctx, client, &Request{Method: http.MethodPut, Url: path, Body: body})
+}
+
+// Patch PATCH запрос с JSON телом
+func Patch[E any](ctx context.Context, client *RestClient, path string, body any) (*structures.HttpResponse[E], error) {
+	return Do[E](ctx, client, &Request{Method: http.MethodPatch, Url: path, Body: body})
+}
+
+// Delete DELETE запрос
+func Delete[E any](ctx context.Context, client *RestClient, path string) (*structures.HttpResponse[E], error) {
+	return Do[E](ctx, client, &Request{Method: http.MethodDelete, Url: path})
+}
+
+// Do выполняет запрос и заполняет HttpResponse:
+// на 2xx data из конверта {success, data} декодируется в Result,
+// на остальные статусы data конверта ошибки попадает в ErrorsMap, а ошибкой возвращается
+// *exception.HttpException со статусом ответа сервиса
+func Do[E any](ctx context.Context, client *RestClient, request *Request) (*structures.HttpResponse[E], error) {
+	httpRequest, err := client.newHttpRequest(ctx, request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpResponse, body, err := client.execute(ctx, httpRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response := &structures.HttpResponse[E]{
+		Status:     httpResponse.Status,
+		Body:       body,
+		StatusCode: httpResponse.StatusCode,
+		Url:        httpRequest.URL.String(),
+		Method:     httpRequest.Method,
+		Headers:    make(map[string]string, len(httpResponse.Header)),
+	}
+
+	for name := range httpResponse.Header {
+		response.Headers[name] = httpResponse.Header.Get(name)
+	}
+
+	if response.StatusCode < http.StatusOK || response.StatusCode >= http.StatusMultipleChoices {
+		response.ErrorsMap = decodeErrorsMap(body)
+
+		return response, newRemoteException(response.StatusCode, response.Status, response.ErrorsMap)
+	}
+
+	if len(bytes.TrimSpace(body)) == 0 {
+		return response, nil
+	}
+
+	envelope := structures.Response[E]{}
+
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return response, fmt.Errorf("decode response of %s %s: %w", response.Method, response.Url, err)
+	}
+
+	response.Result = envelope.Data
+
+	return response, nil
+}
+
+func (c *RestClient) newHttpRequest(ctx context.Context, request *Request) (*http.Request, error) {
+	requestUrl := request.Url
+
+	if c.BaseUrl != "" && !strings.Contains(requestUrl, "://") {
+		requestUrl = c.BaseUrl + "/" + strings.TrimLeft(requestUrl, "/")
+	}
+
+	if len(request.Query) > 0 {
+		separator := "?"
+
+		if strings.Contains(requestUrl, "?") {
+			separator = "&"
+		}
+
+		requestUrl += separator + request.Query.Encode()
+	}
+
+	var body io.Reader
+	isJson := false
+
+	switch b := request.Body.(type) {
+	case nil:
+	case []byte:
+		body = bytes.NewReader(b)
+	case io.Reader:
+		body = b
+	default:
+		encoded, err := json.Marshal(b)
+
+		if err != nil {
+			return nil, err
+		}
+
+		body = bytes.NewReader(encoded)
+		isJson = true
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, request.Method, requestUrl, body)
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpRequest.Header.Set("Accept", "application/json")
+
+	if isJson {
+		httpRequest.Header.Set("Content-Type", "application/json")
+	}
+
+	for name, value := range request.Headers {
+		httpRequest.Header.Set(name, value)
+	}
+
+	return httpRequest, nil
+}
+
+// decodeErrorsMap data из конверта ошибки {success: false, data: {...}}.
+// Если ответ не в конверте (например, ответ ErrorHandler на панику), возвращается весь JSON
+func decodeErrorsMap(body []byte) map[string]interface{} {
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil
+	}
+
+	errorsMap := make(map[string]interface{})
+
+	if len(envelope.Data) > 0 && json.Unmarshal(envelope.Data, &errorsMap) == nil {
+		return errorsMap
+	}
+
+	if err := json.Unmarshal(body, &errorsMap); err != nil {
+		return nil
+	}
+
+	return errorsMap
+}
+
+// newRemoteException ошибка сервиса с сохранением его статуса, сообщения и details.
+// 4xx не отправляются в sentry - это ошибка запроса, а не сбой
+func newRemoteException(statusCode int, status string, errorsMap map[string]interface{}) *exception.HttpException {
+	message := status
+
+	if m, ok := errorsMap["message"].(string); ok && m != "" {
+		message = m
+	}
+
+	var details map[string]any
+
+	if d, ok := errorsMap["details"].(map[string]interface{}); ok {
+		details = d
+	}
+
+	if statusCode < http.StatusInternalServerError {
+		return exception.NewUntrackableHttpException(statusCode, errors.New(message), details)
+	}
+
+	return exception.NewHttpException(statusCode, errors.New(message), details)
+}
+
+func addDebugStatement(ctx context.Context, request *http.Request, status int, duration time.Duration) {
+	if dbg := debug.GetDebugFromContext(ctx); dbg != nil {
+		dbg.AddStatement(DebugCategory, duration, DebugStatement{
+			Method: request.Method,
+			Url:    request.URL.String(),
+			Status: status,
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package httpclient
+
+import (
+	"github.com/exgamer/gosdk-http-core/pkg/constants"
+	gin2 "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// traceHeaders заголовки трейса W3C и B3: если вызывающий код выставил любой из них, трейс не пробрасывается,
+// иначе в запросе окажутся два разных контекста трейса
+var traceHeaders = []string{"traceparent", "b3", "X-B3-TraceId"}
+
+// DefaultForwardedHeaders заголовки идентификации, которые по умолчанию прокидываются в исходящие запросы
+var DefaultForwardedHeaders = []string{
+	constants.UserHeaderName,
+	constants.CompanyIdHeaderName,
+	constants.CityHeaderName,
+}
+
+// NewPropagationTransport оборачивает base транспортом, прокидывающим заголовки входящего запроса.
+// Если base nil - используется http.DefaultTransport, если forwardedHeaders не переданы - DefaultForwardedHeaders
+func NewPropagationTransport(base http.RoundTripper, forwardedHeaders ...string) *PropagationTransport {
+	if len(forwardedHeaders) == 0 {
+		forwardedHeaders = DefaultForwardedHeaders
+	}
+
+	return &PropagationTransport{
+		Base:             base,
+		ForwardedHeaders: forwardedHeaders,
+	}
+}
+
+// NewClient http.Client с PropagationTransport поверх http.DefaultTransport
+func NewClient(timeout time.Duration, forwardedHeaders ...string) *http.Client {
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: NewPropagationTransport(nil, forwardedHeaders...),
+	}
+}
+
+// PropagationTransport http.RoundTripper, который берет HttpInfo из context запроса и проставляет
+// Request-Id, Accept-Language входящего запроса (если он был), заголовки трейса и разрешенные заголовки идентификации
+// (User-Id, Company-Id, City-Id). Заголовки, уже выставленные в исходящем запросе, не перезаписываются,
+// а если выставлен хотя бы один заголовок трейса - трейс не пробрасывается
+type PropagationTransport struct {
+	// Base транспорт, выполняющий запрос, nil - http.DefaultTransport
+	Base http.RoundTripper
+	// ForwardedHeaders allow-list заголовков входящего запроса, которые прокидываются дальше
+	ForwardedHeaders []string
+	// Propagator пропагатор трейса, nil - глобальный otel.GetTextMapPropagator()
+	Propagator propagation.TextMapPropagator
+}
+
+func (t *PropagationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// RoundTripper не должен менять исходный запрос
+	outReq := req.Clone(req.Context())
+
+	if httpInfo := gin2.GetHttpInfoFromContext(req.Context()); httpInfo != nil {
+		setIfEmpty(outReq.Header, constants.RequestIdHeaderName, httpInfo.RequestId)
+
+		if httpInfo.Headers != nil {
+			// не HttpInfo.LanguageCode: он по умолчанию ru, а язык по умолчанию выбирает вызываемый сервис
+			setIfEmpty(outReq.Header, constants.LanguageHeaderName, httpInfo.Headers.Get(constants.LanguageHeaderName))
+
+			for _, name := range t.ForwardedHeaders {
+				setIfEmpty(outReq.Header, name, httpInfo.Headers.Get(name))
+			}
+		}
+	}
+
+	if !slices.ContainsFunc(traceHeaders, func(name string) bool { return outReq.Header.Get(name) != "" }) {
+		t.propagator().Inject(req.Context(), propagation.HeaderCarrier(outReq.Header))
+	}
+
+	return t.base().RoundTrip(outReq)
+}
+
+func (t *PropagationTransport) base() http.RoundTripper {
+	if t.Base != nil {
+		return t.Base
+	}
+
+	return http.DefaultTransport
+}
+
+func (t *PropagationTransport) propagator() propagation.TextMapPropagator {
+	if t.Propagator != nil {
+		return t.Propagator
+	}
+
+	return otel.GetTextMapPropagator()
+}
+
+func setIfEmpty(header http.Header, name string, value string) {
+	if value == "" || header.Get(name) != "" {
+		return
+	}
+
+	header.Set(name, value)
+}
@@ -0,0 +1,138 @@
+package httpclient
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/exgamer/gosdk-http-core/pkg/config"
+	"github.com/exgamer/gosdk-http-core/pkg/constants"
+	"github.com/exgamer/gosdk-http-core/pkg/tracing"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordingTransport запоминает заголовки исходящего запроса
+type recordingTransport struct {
+	header http.Header
+}
+
+func (t *recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	t.header = req.Header
+
+	return httptest.NewRecorder().Result(), nil
+}
+
+func newTraceContext(t *testing.T, incoming http.Header) context.Context {
+	t.Helper()
+
+	traceId, _ := trace.TraceIDFromHex("4bf92f3577b34e6a3736c1d2e7a1a4c1")
+	spanId, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    traceId,
+		SpanID:     spanId,
+		TraceFlags: trace.FlagsSampled,
+	}))
+
+	httpInfo := &config.HttpInfo{RequestId: "req-1", LanguageCode: "ru", Headers: incoming}
+
+	return context.WithValue(ctx, constants.HttpInfoKey, httpInfo)
+}
+
+func roundTrip(t *testing.T, ctx context.Context, header http.Header) http.Header {
+	t.Helper()
+
+	base := &recordingTransport{}
+	transport := &PropagationTransport{Base: base, ForwardedHeaders: DefaultForwardedHeaders, Propagator: tracing.NewPropagator()}
+	request := httptest.NewRequest(http.MethodGet, "http://orders/v1", nil).WithContext(ctx)
+	request.Header = header
+
+	if _, err := transport.RoundTrip(request); err != nil {
+		t.Fatal(err)
+	}
+
+	return base.header
+}
+
+func TestPropagationTransportLanguage(t *testing.T) {
+	tests := []struct {
+		name     string
+		incoming http.Header
+		outgoing http.Header
+		want     string
+	}{
+		{name: "without incoming language", incoming: http.Header{}, outgoing: http.Header{}, want: ""},
+		{name: "incoming language", incoming: http.Header{"Accept-Language": {"kk"}}, outgoing: http.Header{}, want: "kk"},
+		{name: "caller language", incoming: http.Header{"Accept-Language": {"kk"}}, outgoing: http.Header{"Accept-Language": {"en"}}, want: "en"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := roundTrip(t, newTraceContext(t, tt.incoming), tt.outgoing)
+
+			if got := header.Get("Accept-Language"); got != tt.want {
+				t.Fatalf("Accept-Language = %q, want %q", got, tt.want)
+			}
+
+			if got := header.Get("Request-Id"); got != "req-1" {
+				t.Fatalf("Request-Id = %q, want req-1", got)
+			}
+		})
+	}
+}
+
+func TestPropagationTransportTrace(t *testing.T) {
+	tests := []struct {
+		name          string
+		outgoing      http.Header
+		wantInjected  bool
+		wantTraceId   string
+		wantB3TraceId string
+	}{
+		{
+			name:          "inject",
+			outgoing:      http.Header{},
+			wantInjected:  true,
+			wantTraceId:   "00-4bf92f3577b34e6a3736c1d2e7a1a4c1-00f067aa0ba902b7-01",
+			wantB3TraceId: "4bf92f3577b34e6a3736c1d2e7a1a4c1",
+		},
+		{
+			name:        "caller traceparent",
+			outgoing:    http.Header{"Traceparent": {"00-11111111111111111111111111111111-2222222222222222-01"}},
+			wantTraceId: "00-11111111111111111111111111111111-2222222222222222-01",
+		},
+		{name: "caller b3", outgoing: http.Header{"B3": {"11111111111111111111111111111111-2222222222222222-1"}}},
+		{name: "caller b3 multi header", outgoing: http.Header{"X-B3-Traceid": {"11111111111111111111111111111111"}}, wantB3TraceId: "11111111111111111111111111111111"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := roundTrip(t, newTraceContext(t, http.Header{}), tt.outgoing)
+
+			if got := header.Get("traceparent"); got != tt.wantTraceId {
+				t.Fatalf("traceparent = %q, want %q", got, tt.wantTraceId)
+			}
+
+			if got := header.Get("X-B3-TraceId"); got != tt.wantB3TraceId {
+				t.Fatalf("X-B3-TraceId = %q, want %q", got, tt.wantB3TraceId)
+			}
+
+			if got := header.Get("X-B3-SpanId") != ""; got != tt.wantInjected {
+				t.Fatalf("X-B3-SpanId injected = %v, want %v", got, tt.wantInjected)
+			}
+		})
+	}
+}
+
+func TestPropagationTransportKeepsRequest(t *testing.T) {
+	request := httptest.NewRequest(http.MethodGet, "http://orders/v1", nil).WithContext(newTraceContext(t, http.Header{}))
+	transport := &PropagationTransport{Base: &recordingTransport{}, Propagator: tracing.NewPropagator()}
+
+	if _, err := transport.RoundTrip(request); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(request.Header) != 0 {
+		t.Fatalf("original request headers = %v, want none", request.Header)
+	}
+}
@@ -0,0 +1,112 @@
+package jwt
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/exgamer/gosdk-http-core/pkg/constants"
+	"math"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Claims зарегистрированные claims токена. Остальные claims можно получить через Unmarshal
+type Claims struct {
+	Issuer    string       `json:"iss,omitempty"`
+	Subject   string       `json:"sub,omitempty"`
+	Audience  Audience     `json:"aud,omitempty"`
+	ExpiresAt *NumericDate `json:"exp,omitempty"`
+	NotBefore *NumericDate `json:"nbf,omitempty"`
+	IssuedAt  *NumericDate `json:"iat,omitempty"`
+	Id        string       `json:"jti,omitempty"`
+
+	raw json.RawMessage
+}
+
+// Unmarshal декодирует payload токена в свою структуру claims сервиса
+func (c *Claims) Unmarshal(v any) error {
+	return json.Unmarshal(c.raw, v)
+}
+
+// Audience claim aud: строка или массив строк
+type Audience []string
+
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	var single string
+
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+
+		return nil
+	}
+
+	var multiple []string
+
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return err
+	}
+
+	*a = multiple
+
+	return nil
+}
+
+// Contains содержит ли aud хотя бы одно из значений
+func (a Audience) Contains(values ...string) bool {
+	for _, value := range values {
+		if slices.Contains(a, value) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NumericDate время в секундах unix (exp, nbf, iat)
+type NumericDate struct {
+	time.Time
+}
+
+// maxNumericDate 9999-12-31T23:59:59Z: время дальше не имеет смысла в токене, а большие значения
+// переполняют int64 при переводе в time.Time
+const maxNumericDate = 253402300799
+
+// ErrNumericDateRange время claim вне допустимого диапазона
+var ErrNumericDateRange = errors.New("jwt: numeric date is out of range")
+
+func (d *NumericDate) UnmarshalJSON(data []byte) error {
+	seconds, err := strconv.ParseFloat(string(data), 64)
+
+	if err != nil {
+		return err
+	}
+
+	// NaN и Inf тоже не проходят проверку
+	if !(seconds >= -maxNumericDate && seconds <= maxNumericDate) {
+		return ErrNumericDateRange
+	}
+
+	whole, fraction := math.Modf(seconds)
+	d.Time = time.Unix(int64(whole), int64(fraction*float64(time.Second)))
+
+	return nil
+}
+
+func (d NumericDate) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(d.Unix(), 10)), nil
+}
+
+// WithClaims кладет claims в context
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, constants.JwtClaimsKey, claims)
+}
+
+// GetClaimsFromContext claims проверенного токена из context запроса, nil - если запрос без JwtMiddleware
+func GetClaimsFromContext(ctx context.Context) *Claims {
+	if claims, ok := ctx.Value(constants.JwtClaimsKey).(*Claims); ok {
+		return claims
+	}
+
+	return nil
+}
@@ -0,0 +1,50 @@
+package jwt
+
+import (
+	"errors"
+	"github.com/exgamer/gosdk-http-core/pkg/config"
+)
+
+// NewVerifierFromConfig создает Verifier по JWT_* настройкам HttpConfig.
+// Если задано несколько источников ключей, токен проверяется любым из них
+func NewVerifierFromConfig(httpConfig *config.HttpConfig) (*Verifier, error) {
+	providers := make(KeyProviders, 0, 4)
+
+	if httpConfig.JwtHmacSecret != "" {
+		providers = append(providers, StaticKeys{NewHmacKey([]byte(httpConfig.JwtHmacSecret))})
+	}
+
+	if httpConfig.JwtPublicKeyFile != "" {
+		key, err := LoadPublicKeyFile(httpConfig.JwtPublicKeyFile)
+
+		if err != nil {
+			return nil, err
+		}
+
+		providers = append(providers, StaticKeys{key})
+	}
+
+	if httpConfig.JwtJwksUrl != "" {
+		providers = append(providers, NewJwksUrlProvider(httpConfig.JwtJwksUrl, httpConfig.GetJwtJwksRefreshInterval()))
+	}
+
+	if httpConfig.JwtJwksFile != "" {
+		providers = append(providers, NewJwksFileProvider(httpConfig.JwtJwksFile, httpConfig.GetJwtJwksRefreshInterval()))
+	}
+
+	if len(providers) == 0 {
+		return nil, errors.New("jwt: no keys configured, set JWT_HMAC_SECRET, JWT_PUBLIC_KEY_FILE, JWT_JWKS_URL or JWT_JWKS_FILE")
+	}
+
+	var keys KeyProvider = providers
+
+	if len(providers) == 1 {
+		keys = providers[0]
+	}
+
+	return &Verifier{
+		Keys:     keys,
+		Issuer:   httpConfig.JwtIssuer,
+		Audience: httpConfig.GetJwtAudience(),
+	}, nil
+}
@@ -0,0 +1,291 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultJwksRefreshInterval = 15 * time.Minute
+	// DefaultJwksMinRefreshInterval как часто можно пытаться перечитать JWKS (неизвестный kid, недоступный JWKS)
+	DefaultJwksMinRefreshInterval = 10 * time.Second
+	DefaultJwksFetchTimeout       = 10 * time.Second
+)
+
+// NewJwksUrlProvider ключи из JWKS по url (например, https://auth.example.com/.well-known/jwks.json)
+func NewJwksUrlProvider(url string, refreshInterval time.Duration) *JwksProvider {
+	return &JwksProvider{
+		url:             url,
+		client:          &http.Client{Timeout: DefaultJwksFetchTimeout},
+		refreshInterval: refreshInterval,
+	}
+}
+
+// NewJwksFileProvider ключи из JWKS файла (например, примонтированного секрета)
+func NewJwksFileProvider(path string, refreshInterval time.Duration) *JwksProvider {
+	return &JwksProvider{
+		file:            path,
+		refreshInterval: refreshInterval,
+	}
+}
+
+// JwksProvider ключи из JWKS с кешированием. Набор перечитывается раз в refreshInterval
+// и при появлении токена с неизвестным kid (ротация ключей). Загрузка идет вне блокировки и одна на все запросы,
+// попытки не чаще DefaultJwksMinRefreshInterval. Устаревший набор перечитывается в фоне, запросы
+// ждут загрузку, только если подходящих ключей нет. Если перечитать не удалось, используются закешированные ключи
+type JwksProvider struct {
+	url             string
+	file            string
+	client          *http.Client
+	refreshInterval time.Duration
+
+	mu          sync.Mutex
+	keys        []Key
+	refreshedAt time.Time
+	attemptedAt time.Time
+	lastErr     error
+	// refreshing текущая загрузка, nil - загрузки нет
+	refreshing *jwksRefresh
+}
+
+// jwksRefresh загрузка JWKS, которую ждут запросы без подходящих ключей
+type jwksRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+func (p *JwksProvider) Keys(ctx context.Context, kid string) ([]Key, error) {
+	p.mu.Lock()
+
+	refreshInterval := p.refreshInterval
+
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultJwksRefreshInterval
+	}
+
+	keys := filterKeys(p.keys, kid)
+	stale := time.Since(p.refreshedAt) > refreshInterval
+	refresh := p.refreshing
+
+	// после неудачной попытки не перечитываем чаще DefaultJwksMinRefreshInterval, иначе недоступный JWKS
+	// загружался бы на каждый запрос
+	if (stale || len(keys) == 0) && refresh == nil && time.Since(p.attemptedAt) > DefaultJwksMinRefreshInterval {
+		refresh = p.startRefresh(ctx)
+	}
+
+	cachedKeys, lastErr := len(p.keys), p.lastErr
+	p.mu.Unlock()
+
+	if len(keys) > 0 {
+		return keys, nil
+	}
+
+	if refresh == nil {
+		if cachedKeys == 0 {
+			return nil, lastErr
+		}
+
+		return keys, nil
+	}
+
+	select {
+	case <-refresh.done:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.keys) == 0 {
+		return nil, refresh.err
+	}
+
+	return filterKeys(p.keys, kid), nil
+}
+
+// startRefresh запускает загрузку JWKS. Вызывается под p.mu
+func (p *JwksProvider) startRefresh(ctx context.Context) *jwksRefresh {
+	refresh := &jwksRefresh{done: make(chan struct{})}
+	p.refreshing = refresh
+	p.attemptedAt = time.Now()
+
+	// JWKS общий для всех запросов, отмена конкретного запроса не должна прерывать загрузку
+	ctx = context.WithoutCancel(ctx)
+
+	go func() {
+		keys, err := p.load(ctx)
+
+		p.mu.Lock()
+
+		if err == nil {
+			p.keys = keys
+			p.refreshedAt = time.Now()
+		}
+
+		p.lastErr = err
+		p.refreshing = nil
+		refresh.err = err
+		p.mu.Unlock()
+
+		close(refresh.done)
+	}()
+
+	return refresh
+}
+
+func (p *JwksProvider) load(ctx context.Context) ([]Key, error) {
+	data, err := p.read(ctx)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ParseJwks(data)
+}
+
+func (p *JwksProvider) read(ctx context.Context) ([]byte, error) {
+	if p.file != "" {
+		return os.ReadFile(p.file)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, p.url, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := p.client.Do(request)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("jwt: fetch jwks %s: %s", p.url, response.Status)
+	}
+
+	return io.ReadAll(io.LimitReader(response.Body, 1<<20))
+}
+
+// ParseJwks ключи подписи из JWKS (RSA, EC P-256). Ключи шифрования и неподдерживаемые типы пропускаются.
+// Симметричные ключи (oct) тоже пропускаются: JWKS публичный, и любой, кто его прочитал, смог бы подписать токен.
+// HMAC секрет задается только JWT_HMAC_SECRET
+func ParseJwks(data []byte) ([]Key, error) {
+	jwks := struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			Use string `json:"use"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+			Crv string `json:"crv"`
+			X   string `json:"x"`
+			Y   string `json:"y"`
+		} `json:"keys"`
+	}{}
+
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("jwt: parse jwks: %w", err)
+	}
+
+	keys := make([]Key, 0, len(jwks.Keys))
+
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+
+		var key any
+		var err error
+
+		switch jwk.Kty {
+		case "RSA":
+			key, err = parseRsaJwk(jwk.N, jwk.E)
+		case "EC":
+			if jwk.Crv != elliptic.P256().Params().Name {
+				continue
+			}
+
+			key, err = parseEcJwk(jwk.X, jwk.Y)
+		default:
+			continue
+		}
+
+		if err != nil {
+			return nil, fmt.Errorf("jwt: parse jwk %s: %w", jwk.Kid, err)
+		}
+
+		keys = append(keys, Key{Id: jwk.Kid, Key: key})
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("jwt: jwks has no signing keys")
+	}
+
+	return keys, nil
+}
+
+func parseRsaJwk(n string, e string) (*rsa.PublicKey, error) {
+	modulus, err := base64.RawURLEncoding.DecodeString(n)
+
+	if err != nil {
+		return nil, err
+	}
+
+	exponent, err := base64.RawURLEncoding.DecodeString(e)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(exponent) == 0 || len(exponent) > 4 {
+		return nil, errors.New("invalid rsa exponent")
+	}
+
+	return &rsa.PublicKey{
+		N: new(big.Int).SetBytes(modulus),
+		E: int(new(big.Int).SetBytes(exponent).Int64()),
+	}, nil
+}
+
+func parseEcJwk(x string, y string) (*ecdsa.PublicKey, error) {
+	xBytes, err := base64.RawURLEncoding.DecodeString(x)
+
+	if err != nil {
+		return nil, err
+	}
+
+	yBytes, err := base64.RawURLEncoding.DecodeString(y)
+
+	if err != nil {
+		return nil, err
+	}
+
+	publicKey := &ecdsa.PublicKey{
+		Curve: elliptic.P256(),
+		X:     new(big.Int).SetBytes(xBytes),
+		Y:     new(big.Int).SetBytes(yBytes),
+	}
+
+	// ECDH проверяет, что точка лежит на кривой
+	if _, err := publicKey.ECDH(); err != nil {
+		return nil, err
+	}
+
+	return publicKey, nil
+}
@@ -0,0 +1,121 @@
+package jwt
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testJwks JWKS с публичным ключом testRsaKey
+var testJwks = fmt.Sprintf(`{"keys":[{"kty":"RSA","kid":"rs","use":"sig","n":%q,"e":%q}]}`,
+	base64.RawURLEncoding.EncodeToString(testRsaKey.N.Bytes()),
+	base64.RawURLEncoding.EncodeToString(big.NewInt(int64(testRsaKey.E)).Bytes()),
+)
+
+func TestJwksProviderUsesCachedKeysWhileJwksIsDown(t *testing.T) {
+	var fetches atomic.Int32
+	var down atomic.Bool
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fetches.Add(1)
+
+		if down.Load() {
+			time.Sleep(100 * time.Millisecond)
+			w.WriteHeader(http.StatusInternalServerError)
+
+			return
+		}
+
+		_, _ = w.Write([]byte(testJwks))
+	}))
+	defer server.Close()
+
+	provider := NewJwksUrlProvider(server.URL, time.Millisecond)
+
+	if keys, err := provider.Keys(context.Background(), "rs"); err != nil || len(keys) != 1 {
+		t.Fatalf("Keys() = %d keys, %v, want 1 key", len(keys), err)
+	}
+
+	down.Store(true)
+	time.Sleep(2 * time.Millisecond)
+	// набор устарел и попытку можно повторить
+	provider.attemptedAt = time.Time{}
+
+	start := time.Now()
+	var wg sync.WaitGroup
+
+	for range 50 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if keys, err := provider.Keys(context.Background(), "rs"); err != nil || len(keys) != 1 {
+				t.Errorf("Keys() = %d keys, %v, want cached key", len(keys), err)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	// запросы не ждут загрузку устаревшего набора
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("Keys() with stale cache took %s", elapsed)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("fetches = %d, want 2: one refresh for all requests", got)
+	}
+
+	// после неудачной попытки JWKS не перечитывается до DefaultJwksMinRefreshInterval
+	if keys, err := provider.Keys(context.Background(), "rs"); err != nil || len(keys) != 1 {
+		t.Fatalf("Keys() = %d keys, %v, want cached key", len(keys), err)
+	}
+
+	time.Sleep(150 * time.Millisecond)
+
+	if got := fetches.Load(); got != 2 {
+		t.Fatalf("fetches = %d, want 2: failed refresh must be throttled", got)
+	}
+}
+
+func TestJwksProviderWithoutKeysReturnsError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	provider := NewJwksUrlProvider(server.URL, 0)
+
+	for range 2 {
+		if _, err := provider.Keys(context.Background(), "rs"); err == nil {
+			t.Fatal("Keys() error = nil, want fetch error")
+		}
+	}
+}
+
+func TestParseJwks(t *testing.T) {
+	keys, err := ParseJwks([]byte(testJwks))
+
+	if err != nil || len(keys) != 1 || keys[0].Id != "rs" || !testRsaKey.PublicKey.Equal(keys[0].Key) {
+		t.Fatalf("ParseJwks() = %+v, %v", keys, err)
+	}
+
+	if _, err := ParseJwks([]byte(`{"keys":[{"kty":"RSA","use":"enc","n":"AQAB","e":"AQAB"}]}`)); err == nil {
+		t.Fatal("ParseJwks() without signing keys error = nil")
+	}
+
+	// симметричный ключ из публичного JWKS позволил бы подписать токен любому
+	if _, err := ParseJwks([]byte(`{"keys":[{"kty":"oct","kid":"hs","k":"dGVzdC1zZWNyZXQ"}]}`)); err == nil {
+		t.Fatal("ParseJwks() with oct key only error = nil")
+	}
+}
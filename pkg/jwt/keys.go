@@ -0,0 +1,127 @@
+package jwt
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+)
+
+const (
+	AlgorithmHS256 = "HS256"
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+)
+
+// Key ключ проверки подписи: []byte для HS256, *rsa.PublicKey для RS256, *ecdsa.PublicKey (P-256) для ES256
+type Key struct {
+	// Id kid ключа, пустой - ключ подходит для токена с любым kid
+	Id  string
+	Key any
+}
+
+// KeyProvider источник ключей проверки подписи
+type KeyProvider interface {
+	// Keys ключи, которыми может быть подписан токен с kid
+	Keys(ctx context.Context, kid string) ([]Key, error)
+}
+
+// StaticKeys неизменяемый набор ключей
+type StaticKeys []Key
+
+func (k StaticKeys) Keys(_ context.Context, kid string) ([]Key, error) {
+	return filterKeys(k, kid), nil
+}
+
+// KeyProviders объединяет несколько источников ключей, например статический ключ и JWKS
+type KeyProviders []KeyProvider
+
+func (p KeyProviders) Keys(ctx context.Context, kid string) ([]Key, error) {
+	keys := make([]Key, 0)
+	var errs []error
+
+	for _, provider := range p {
+		providerKeys, err := provider.Keys(ctx, kid)
+
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		keys = append(keys, providerKeys...)
+	}
+
+	if len(keys) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	return keys, nil
+}
+
+// NewHmacKey ключ HS256
+func NewHmacKey(secret []byte) Key {
+	return Key{Key: secret}
+}
+
+// ParsePublicKeyPem публичный ключ RSA или ECDSA из PEM (PUBLIC KEY, RSA PUBLIC KEY или CERTIFICATE)
+func ParsePublicKeyPem(data []byte) (Key, error) {
+	block, _ := pem.Decode(data)
+
+	if block == nil {
+		return Key{}, errors.New("jwt: public key is not PEM encoded")
+	}
+
+	var publicKey any
+	var err error
+
+	switch block.Type {
+	case "RSA PUBLIC KEY":
+		publicKey, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+
+		if cert, err = x509.ParseCertificate(block.Bytes); err == nil {
+			publicKey = cert.PublicKey
+		}
+	default:
+		publicKey, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+
+	if err != nil {
+		return Key{}, err
+	}
+
+	switch publicKey.(type) {
+	case *rsa.PublicKey, *ecdsa.PublicKey:
+		return Key{Key: publicKey}, nil
+	default:
+		return Key{}, errors.New("jwt: unsupported public key type")
+	}
+}
+
+// LoadPublicKeyFile публичный ключ RSA или ECDSA из PEM файла
+func LoadPublicKeyFile(path string) (Key, error) {
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return Key{}, err
+	}
+
+	return ParsePublicKeyPem(data)
+}
+
+func filterKeys(keys []Key, kid string) []Key {
+	filtered := make([]Key, 0, len(keys))
+
+	for _, key := range keys {
+		if kid == "" || key.Id == "" || key.Id == kid {
+			filtered = append(filtered, key)
+		}
+	}
+
+	return filtered
+}
@@ -0,0 +1,204 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+	"time"
+)
+
+// DefaultLeeway допустимое расхождение часов при проверке exp и nbf
+const DefaultLeeway = 30 * time.Second
+
+var (
+	ErrTokenMissing      = errors.New("authorization token is missing")
+	ErrTokenMalformed    = errors.New("token is malformed")
+	ErrTokenAlgorithm    = errors.New("token signing algorithm is not allowed")
+	ErrTokenSignature    = errors.New("token signature is invalid")
+	ErrTokenExpired      = errors.New("token is expired")
+	ErrTokenNotYetValid  = errors.New("token is not valid yet")
+	ErrTokenIssuer       = errors.New("token issuer is invalid")
+	ErrTokenAudience     = errors.New("token audience is invalid")
+	ErrTokenNoExpiration = errors.New("token has no expiration")
+	// ErrKeysUnavailable не удалось получить ключи проверки (например, JWKS недоступен) - ошибка сервера, а не токена
+	ErrKeysUnavailable = errors.New("jwt keys are unavailable")
+)
+
+// Verifier проверяет подпись и claims JWT
+type Verifier struct {
+	Keys KeyProvider
+	// Algorithms разрешенные алгоритмы, пустой - HS256, RS256, ES256
+	Algorithms []string
+	// Issuer ожидаемый iss, пустой - не проверяется
+	Issuer string
+	// Audience допустимые aud (токен должен содержать хотя бы одно), пустой - не проверяется
+	Audience []string
+	// Leeway допустимое расхождение часов, 0 - DefaultLeeway
+	Leeway time.Duration
+}
+
+// Verify проверяет токен и возвращает его claims
+func (v *Verifier) Verify(ctx context.Context, token string) (*Claims, error) {
+	if token == "" {
+		return nil, ErrTokenMissing
+	}
+
+	parts := strings.Split(token, ".")
+
+	if len(parts) != 3 {
+		return nil, ErrTokenMalformed
+	}
+
+	header := struct {
+		Alg string `json:"alg"`
+		Kid string `json:"kid"`
+	}{}
+
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if !v.isAllowedAlgorithm(header.Alg) {
+		return nil, ErrTokenAlgorithm
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	keys, err := v.Keys.Keys(ctx, header.Kid)
+
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrKeysUnavailable, err)
+	}
+
+	signingInput := parts[0] + "." + parts[1]
+
+	if !slices.ContainsFunc(keys, func(key Key) bool {
+		return verifySignature(header.Alg, key.Key, signingInput, signature)
+	}) {
+		return nil, ErrTokenSignature
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+
+	if err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	claims := &Claims{raw: payload}
+
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, ErrTokenMalformed
+	}
+
+	if err := v.validateClaims(claims); err != nil {
+		return nil, err
+	}
+
+	return claims, nil
+}
+
+func (v *Verifier) validateClaims(claims *Claims) error {
+	leeway := v.Leeway
+
+	if leeway <= 0 {
+		leeway = DefaultLeeway
+	}
+
+	now := time.Now()
+
+	if claims.ExpiresAt == nil {
+		return ErrTokenNoExpiration
+	}
+
+	if now.After(claims.ExpiresAt.Add(leeway)) {
+		return ErrTokenExpired
+	}
+
+	if claims.NotBefore != nil && now.Add(leeway).Before(claims.NotBefore.Time) {
+		return ErrTokenNotYetValid
+	}
+
+	if v.Issuer != "" && claims.Issuer != v.Issuer {
+		return ErrTokenIssuer
+	}
+
+	if len(v.Audience) > 0 && !claims.Audience.Contains(v.Audience...) {
+		return ErrTokenAudience
+	}
+
+	return nil
+}
+
+func (v *Verifier) isAllowedAlgorithm(alg string) bool {
+	if len(v.Algorithms) == 0 {
+		return alg == AlgorithmHS256 || alg == AlgorithmRS256 || alg == AlgorithmES256
+	}
+
+	return slices.Contains(v.Algorithms, alg)
+}
+
+// verifySignature проверяет подпись; тип ключа должен соответствовать алгоритму,
+// чтобы публичный RSA ключ нельзя было использовать как HMAC секрет
+func verifySignature(alg string, key any, signingInput string, signature []byte) bool {
+	switch alg {
+	case AlgorithmHS256:
+		secret, ok := key.([]byte)
+
+		if !ok || len(secret) == 0 {
+			return false
+		}
+
+		mac := hmac.New(sha256.New, secret)
+		mac.Write([]byte(signingInput))
+
+		return hmac.Equal(signature, mac.Sum(nil))
+	case AlgorithmRS256:
+		publicKey, ok := key.(*rsa.PublicKey)
+
+		if !ok {
+			return false
+		}
+
+		hash := sha256.Sum256([]byte(signingInput))
+
+		return rsa.VerifyPKCS1v15(publicKey, crypto.SHA256, hash[:], signature) == nil
+	case AlgorithmES256:
+		publicKey, ok := key.(*ecdsa.PublicKey)
+
+		if !ok || publicKey.Curve.Params().BitSize != 256 || len(signature) != 64 {
+			return false
+		}
+
+		hash := sha256.Sum256([]byte(signingInput))
+		r := new(big.Int).SetBytes(signature[:32])
+		s := new(big.Int).SetBytes(signature[32:])
+
+		return ecdsa.Verify(publicKey, hash[:], r, s)
+	default:
+		return false
+	}
+}
+
+func decodeSegment(segment string, v any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
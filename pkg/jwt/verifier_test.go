@@ -0,0 +1,235 @@
+package jwt
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testSecret      = []byte("test-secret")
+	testRsaKey, _   = rsa.GenerateKey(rand.Reader, 2048)
+	testEcdsaKey, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+)
+
+// signToken подписывает токен с заголовком header и payload claims ключом key
+func signToken(t *testing.T, header map[string]any, claims map[string]any, key any) string {
+	t.Helper()
+
+	headerJson, _ := json.Marshal(header)
+	claimsJson, _ := json.Marshal(claims)
+	signingInput := base64.RawURLEncoding.EncodeToString(headerJson) + "." + base64.RawURLEncoding.EncodeToString(claimsJson)
+	hash := sha256.Sum256([]byte(signingInput))
+
+	var signature []byte
+
+	switch key := key.(type) {
+	case []byte:
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(signingInput))
+		signature = mac.Sum(nil)
+	case *rsa.PrivateKey:
+		var err error
+
+		if signature, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:]); err != nil {
+			t.Fatal(err)
+		}
+	case *ecdsa.PrivateKey:
+		r, s, err := ecdsa.Sign(rand.Reader, key, hash[:])
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		signature = make([]byte, 64)
+		r.FillBytes(signature[:32])
+		s.FillBytes(signature[32:])
+	}
+
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func validClaims() map[string]any {
+	return map[string]any{
+		"sub": "42",
+		"iss": "auth",
+		"aud": "orders",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+}
+
+func withClaim(name string, value any) map[string]any {
+	claims := validClaims()
+
+	if value == nil {
+		delete(claims, name)
+	} else {
+		claims[name] = value
+	}
+
+	return claims
+}
+
+func TestVerifierVerify(t *testing.T) {
+	rsaPublicDer, _ := x509.MarshalPKIXPublicKey(&testRsaKey.PublicKey)
+
+	keys := StaticKeys{
+		{Id: "hs", Key: testSecret},
+		{Id: "rs", Key: &testRsaKey.PublicKey},
+		{Id: "es", Key: &testEcdsaKey.PublicKey},
+	}
+
+	tests := []struct {
+		name       string
+		algorithms []string
+		token      string
+		wantErr    error
+	}{
+		{
+			name:  "HS256",
+			token: signToken(t, map[string]any{"alg": "HS256", "kid": "hs"}, validClaims(), testSecret),
+		},
+		{
+			name:  "RS256",
+			token: signToken(t, map[string]any{"alg": "RS256", "kid": "rs"}, validClaims(), testRsaKey),
+		},
+		{
+			name:  "ES256",
+			token: signToken(t, map[string]any{"alg": "ES256", "kid": "es"}, validClaims(), testEcdsaKey),
+		},
+		{
+			name:    "empty token",
+			token:   "",
+			wantErr: ErrTokenMissing,
+		},
+		{
+			name:    "two segments",
+			token:   "a.b",
+			wantErr: ErrTokenMalformed,
+		},
+		{
+			name:    "alg none",
+			token:   signToken(t, map[string]any{"alg": "none"}, validClaims(), nil),
+			wantErr: ErrTokenAlgorithm,
+		},
+		{
+			name:       "alg not allowed",
+			algorithms: []string{AlgorithmRS256},
+			token:      signToken(t, map[string]any{"alg": "HS256", "kid": "hs"}, validClaims(), testSecret),
+			wantErr:    ErrTokenAlgorithm,
+		},
+		{
+			// публичный RSA ключ не должен приниматься как HMAC секрет
+			name:    "HS256 signed with RSA public key",
+			token:   signToken(t, map[string]any{"alg": "HS256", "kid": "rs"}, validClaims(), rsaPublicDer),
+			wantErr: ErrTokenSignature,
+		},
+		{
+			name:    "wrong secret",
+			token:   signToken(t, map[string]any{"alg": "HS256", "kid": "hs"}, validClaims(), []byte("other")),
+			wantErr: ErrTokenSignature,
+		},
+		{
+			name:    "unknown kid",
+			token:   signToken(t, map[string]any{"alg": "HS256", "kid": "other"}, validClaims(), testSecret),
+			wantErr: ErrTokenSignature,
+		},
+		{
+			name:    "expired",
+			token:   signToken(t, map[string]any{"alg": "HS256"}, withClaim("exp", time.Now().Add(-time.Minute).Unix()), testSecret),
+			wantErr: ErrTokenExpired,
+		},
+		{
+			name:  "expired within leeway",
+			token: signToken(t, map[string]any{"alg": "HS256"}, withClaim("exp", time.Now().Add(-10*time.Second).Unix()), testSecret),
+		},
+		{
+			name:    "without exp",
+			token:   signToken(t, map[string]any{"alg": "HS256"}, withClaim("exp", nil), testSecret),
+			wantErr: ErrTokenNoExpiration,
+		},
+		{
+			// переполнение int64 при переводе в time.Time дало бы время в прошлом или будущем
+			name:    "exp out of range",
+			token:   signToken(t, map[string]any{"alg": "HS256"}, withClaim("exp", 1e300), testSecret),
+			wantErr: ErrTokenMalformed,
+		},
+		{
+			name:    "nbf out of range",
+			token:   signToken(t, map[string]any{"alg": "HS256"}, withClaim("nbf", -1e19), testSecret),
+			wantErr: ErrTokenMalformed,
+		},
+		{
+			name:    "not valid yet",
+			token:   signToken(t, map[string]any{"alg": "HS256"}, withClaim("nbf", time.Now().Add(time.Minute).Unix()), testSecret),
+			wantErr: ErrTokenNotYetValid,
+		},
+		{
+			name:    "wrong issuer",
+			token:   signToken(t, map[string]any{"alg": "HS256"}, withClaim("iss", "other"), testSecret),
+			wantErr: ErrTokenIssuer,
+		},
+		{
+			name:    "wrong audience",
+			token:   signToken(t, map[string]any{"alg": "HS256"}, withClaim("aud", []string{"billing"}), testSecret),
+			wantErr: ErrTokenAudience,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := &Verifier{Keys: keys, Algorithms: tt.algorithms, Issuer: "auth", Audience: []string{"orders"}}
+			claims, err := verifier.Verify(context.Background(), tt.token)
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+
+			if tt.wantErr == nil && claims.Subject != "42" {
+				t.Fatalf("Verify() subject = %q, want 42", claims.Subject)
+			}
+		})
+	}
+}
+
+func TestVerifierTamperedPayload(t *testing.T) {
+	token := signToken(t, map[string]any{"alg": "HS256"}, validClaims(), testSecret)
+	forged := signToken(t, map[string]any{"alg": "HS256"}, withClaim("sub", "1"), []byte("other"))
+
+	// payload другого токена с исходной подписью
+	parts := strings.Split(token, ".")
+	parts[1] = strings.Split(forged, ".")[1]
+
+	verifier := &Verifier{Keys: StaticKeys{NewHmacKey(testSecret)}}
+
+	if _, err := verifier.Verify(context.Background(), strings.Join(parts, ".")); !errors.Is(err, ErrTokenSignature) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrTokenSignature)
+	}
+}
+
+func TestVerifierKeysUnavailable(t *testing.T) {
+	verifier := &Verifier{Keys: KeyProviders{failingKeys{}}}
+	token := signToken(t, map[string]any{"alg": "HS256"}, validClaims(), testSecret)
+
+	if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, ErrKeysUnavailable) {
+		t.Fatalf("Verify() error = %v, want %v", err, ErrKeysUnavailable)
+	}
+}
+
+type failingKeys struct{}
+
+func (failingKeys) Keys(context.Context, string) ([]Key, error) {
+	return nil, errors.New("jwks is down")
+}
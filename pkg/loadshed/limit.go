@@ -0,0 +1,139 @@
+package loadshed
+
+import (
+	"math"
+	"time"
+)
+
+// Sample результат обработки запроса, по которому адаптивный лимит пересчитывается
+type Sample struct {
+	// Rtt время обработки запроса
+	Rtt time.Duration
+	// InFlight сколько запросов обрабатывалось в момент начала запроса
+	InFlight int
+	// Dropped запрос завершился ошибкой сервера или таймаутом - признак перегрузки
+	Dropped bool
+}
+
+// Limit алгоритм лимита одновременных запросов. Вызовы сериализует Limiter
+type Limit interface {
+	// Get текущий лимит
+	Get() int
+	// Update пересчитывает лимит по результату запроса
+	Update(sample Sample)
+}
+
+// StaticLimit фиксированный лимит
+type StaticLimit int
+
+func (l StaticLimit) Get() int {
+	return int(l)
+}
+
+func (l StaticLimit) Update(Sample) {}
+
+// NewAimdLimit AIMD лимит: +1 за каждый успешный запрос при загрузке больше половины лимита,
+// умножение на 0.9 при ошибке или запросе дольше timeout
+func NewAimdLimit(initial int, min int, max int, timeout time.Duration) *AimdLimit {
+	return &AimdLimit{limit: float64(initial), Min: min, Max: max, Timeout: timeout, BackoffRatio: 0.9}
+}
+
+// AimdLimit additive increase / multiplicative decrease лимит
+type AimdLimit struct {
+	Min int
+	Max int
+	// Timeout время ответа, после которого запрос считается признаком перегрузки
+	Timeout time.Duration
+	// BackoffRatio во сколько раз уменьшается лимит при перегрузке
+	BackoffRatio float64
+
+	limit float64
+}
+
+func (l *AimdLimit) Get() int {
+	return int(l.limit)
+}
+
+func (l *AimdLimit) Update(sample Sample) {
+	switch {
+	case sample.Dropped || (l.Timeout > 0 && sample.Rtt > l.Timeout):
+		l.limit = l.limit * l.BackoffRatio
+	case sample.InFlight*2 >= int(l.limit):
+		l.limit++
+	}
+
+	l.limit = clampLimit(l.limit, l.Min, l.Max)
+}
+
+// NewGradientLimit gradient лимит: сравнивает текущее время ответа с долгосрочным средним
+// и уменьшает лимит, когда запросы начинают ждать в очереди
+func NewGradientLimit(initial int, min int, max int) *GradientLimit {
+	return &GradientLimit{limit: float64(initial), Min: min, Max: max, Tolerance: 1.5, Smoothing: 0.2, LongWindow: 600}
+}
+
+// GradientLimit адаптивный лимит по градиенту времени ответа
+type GradientLimit struct {
+	Min int
+	Max int
+	// Tolerance во сколько раз время ответа может превысить среднее без уменьшения лимита
+	Tolerance float64
+	// Smoothing доля нового значения лимита при пересчете
+	Smoothing float64
+	// LongWindow за сколько запросов усредняется долгосрочное время ответа
+	LongWindow int
+
+	limit   float64
+	longRtt float64
+}
+
+func (l *GradientLimit) Get() int {
+	return int(l.limit)
+}
+
+func (l *GradientLimit) Update(sample Sample) {
+	rtt := float64(sample.Rtt)
+
+	if rtt <= 0 {
+		return
+	}
+
+	if l.longRtt == 0 {
+		l.longRtt = rtt
+	} else {
+		l.longRtt += (rtt - l.longRtt) / float64(l.LongWindow)
+	}
+
+	// после долгой перегрузки среднее завышено - быстрее возвращаем его к текущему времени ответа
+	if l.longRtt/rtt > 2 {
+		l.longRtt *= 0.95
+	}
+
+	// сервис недогружен - нет оснований увеличивать лимит
+	if sample.InFlight*2 < int(l.limit) && !sample.Dropped {
+		return
+	}
+
+	gradient := math.Max(0.5, math.Min(1, l.Tolerance*l.longRtt/rtt))
+
+	if sample.Dropped {
+		gradient = 0.5
+	}
+
+	// запас на очередь, чтобы лимит мог расти, пока время ответа не меняется
+	queueSize := math.Sqrt(l.limit)
+	newLimit := l.limit*gradient + queueSize
+
+	l.limit = clampLimit(l.limit*(1-l.Smoothing)+newLimit*l.Smoothing, l.Min, l.Max)
+}
+
+func clampLimit(limit float64, min int, max int) float64 {
+	if min < 1 {
+		min = 1
+	}
+
+	if max > 0 && limit > float64(max) {
+		return float64(max)
+	}
+
+	return math.Max(float64(min), limit)
+}
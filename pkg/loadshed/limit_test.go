@@ -0,0 +1,130 @@
+package loadshed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAimdLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		initial int
+		sample  Sample
+		want    int
+	}{
+		{name: "increase under load", initial: 10, sample: Sample{Rtt: time.Millisecond, InFlight: 5}, want: 11},
+		{name: "no increase when underloaded", initial: 10, sample: Sample{Rtt: time.Millisecond, InFlight: 4}, want: 10},
+		{name: "decrease on drop", initial: 10, sample: Sample{Rtt: time.Millisecond, InFlight: 5, Dropped: true}, want: 9},
+		{name: "decrease on timeout", initial: 10, sample: Sample{Rtt: 2 * time.Second, InFlight: 5}, want: 9},
+		{name: "max", initial: 20, sample: Sample{Rtt: time.Millisecond, InFlight: 20}, want: 20},
+		{name: "min", initial: 2, sample: Sample{Rtt: time.Millisecond, InFlight: 2, Dropped: true}, want: 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := NewAimdLimit(tt.initial, 2, 20, time.Second)
+			limit.Update(tt.sample)
+
+			if got := limit.Get(); got != tt.want {
+				t.Fatalf("limit = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAimdLimitRecovers(t *testing.T) {
+	limit := NewAimdLimit(100, 1, 200, time.Second)
+
+	for range 10 {
+		limit.Update(Sample{Rtt: time.Millisecond, InFlight: limit.Get(), Dropped: true})
+	}
+
+	dropped := limit.Get()
+
+	if dropped >= 40 {
+		t.Fatalf("limit after drops = %d, want < 40", dropped)
+	}
+
+	for range 10 {
+		limit.Update(Sample{Rtt: time.Millisecond, InFlight: limit.Get()})
+	}
+
+	if got := limit.Get(); got != dropped+10 {
+		t.Fatalf("limit after recovery = %d, want %d", got, dropped+10)
+	}
+}
+
+// updateGradient n раз обновляет лимит запросами с временем ответа rtt при полной загрузке
+func updateGradient(limit *GradientLimit, n int, rtt time.Duration, dropped bool) {
+	for range n {
+		limit.Update(Sample{Rtt: rtt, InFlight: limit.Get(), Dropped: dropped})
+	}
+}
+
+func TestGradientLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		warmup  int
+		rtt     time.Duration
+		dropped bool
+		grows   bool
+	}{
+		{name: "grows while rtt is stable", warmup: 50, rtt: 10 * time.Millisecond, grows: true},
+		{name: "shrinks when rtt grows", warmup: 50, rtt: 100 * time.Millisecond},
+		{name: "shrinks on drops", warmup: 50, rtt: 10 * time.Millisecond, dropped: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := NewGradientLimit(10, 1, 1000)
+			updateGradient(limit, tt.warmup, 10*time.Millisecond, false)
+			before := limit.Get()
+
+			updateGradient(limit, 10, tt.rtt, tt.dropped)
+
+			if got := limit.Get(); (got > before) != tt.grows || got == before {
+				t.Fatalf("limit = %d, before = %d, want grows = %v", got, before, tt.grows)
+			}
+		})
+	}
+}
+
+func TestGradientLimitBounds(t *testing.T) {
+	limit := NewGradientLimit(10, 5, 20)
+
+	updateGradient(limit, 100, 10*time.Millisecond, false)
+
+	if got := limit.Get(); got != 20 {
+		t.Fatalf("limit = %d, want max 20", got)
+	}
+
+	updateGradient(limit, 100, 10*time.Millisecond, true)
+
+	if got := limit.Get(); got != 5 {
+		t.Fatalf("limit = %d, want min 5", got)
+	}
+}
+
+func TestGradientLimitIgnores(t *testing.T) {
+	tests := []struct {
+		name   string
+		sample Sample
+	}{
+		{name: "underloaded", sample: Sample{Rtt: time.Second, InFlight: 1}},
+		{name: "without rtt", sample: Sample{InFlight: 10, Dropped: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limit := NewGradientLimit(10, 1, 100)
+			updateGradient(limit, 10, 10*time.Millisecond, false)
+			before := limit.Get()
+
+			limit.Update(tt.sample)
+
+			if got := limit.Get(); got != before {
+				t.Fatalf("limit = %d, want %d", got, before)
+			}
+		})
+	}
+}
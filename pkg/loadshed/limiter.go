@@ -0,0 +1,150 @@
+package loadshed
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultRetryAfter через сколько клиенту предлагается повторить отклоненный запрос
+const DefaultRetryAfter = time.Second
+
+// Priority класс приоритета роута. При перегрузке первыми отклоняются запросы с низким приоритетом
+type Priority int
+
+const (
+	// PriorityCritical может занять весь лимит (оплата, авторизация)
+	PriorityCritical Priority = iota
+	// PriorityNormal может занять 90% лимита
+	PriorityNormal
+	// PriorityLow может занять 50% лимита (отчеты, выгрузки, фоновые запросы)
+	PriorityLow
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityCritical:
+		return "critical"
+	case PriorityLow:
+		return "low"
+	default:
+		return "normal"
+	}
+}
+
+// share доля лимита, которую могут занять запросы приоритета
+func (p Priority) share() float64 {
+	switch p {
+	case PriorityCritical:
+		return 1
+	case PriorityLow:
+		return 0.5
+	default:
+		return 0.9
+	}
+}
+
+// NewLimiter ограничитель одновременных запросов
+func NewLimiter(name string, limit Limit) *Limiter {
+	return &Limiter{Name: name, Limit: limit, RetryAfter: DefaultRetryAfter, DefaultPriority: PriorityNormal}
+}
+
+// Limiter ограничивает число одновременно обрабатываемых запросов.
+// Запросы сверх лимита сразу отклоняются, а не ждут в очереди
+type Limiter struct {
+	// Name label limiter в метриках
+	Name  string
+	Limit Limit
+	// RetryAfter значение Retry-After отклоненного запроса
+	RetryAfter time.Duration
+	// DefaultPriority приоритет роутов, для которых он не задан
+	DefaultPriority Priority
+
+	mu       sync.Mutex
+	inFlight int
+	routes   []routePriority
+}
+
+// routePriority приоритет роутов по префиксу шаблона пути
+type routePriority struct {
+	prefix   string
+	priority Priority
+}
+
+// SetRoutePriority задает приоритет роутов, шаблон пути которых начинается с prefix.
+// Если роут подходит под несколько префиксов, берется самый длинный
+func (l *Limiter) SetRoutePriority(prefix string, priority Priority) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for i, route := range l.routes {
+		if route.prefix == prefix {
+			l.routes[i].priority = priority
+
+			return
+		}
+	}
+
+	l.routes = append(l.routes, routePriority{prefix: prefix, priority: priority})
+
+	sort.Slice(l.routes, func(i, j int) bool {
+		return len(l.routes[i].prefix) > len(l.routes[j].prefix)
+	})
+}
+
+// GetRoutePriority приоритет роута по шаблону пути
+func (l *Limiter) GetRoutePriority(route string) Priority {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, r := range l.routes {
+		if strings.HasPrefix(route, r.prefix) {
+			return r.priority
+		}
+	}
+
+	return l.DefaultPriority
+}
+
+// Acquire занимает место для запроса. ok=false - запрос нужно отклонить.
+// release вызывается по завершении запроса, dropped - запрос завершился ошибкой сервера или таймаутом
+func (l *Limiter) Acquire(priority Priority) (release func(dropped bool), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	allowed := int(math.Ceil(float64(l.Limit.Get()) * priority.share()))
+
+	if l.inFlight >= max(allowed, 1) {
+		return nil, false
+	}
+
+	l.inFlight++
+	inFlight := l.inFlight
+	start := time.Now()
+
+	return func(dropped bool) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		l.inFlight--
+		l.Limit.Update(Sample{Rtt: time.Since(start), InFlight: inFlight, Dropped: dropped})
+	}, true
+}
+
+// GetLimit текущий лимит
+func (l *Limiter) GetLimit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.Limit.Get()
+}
+
+// GetInFlight сколько запросов сейчас обрабатывается
+func (l *Limiter) GetInFlight() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.inFlight
+}
@@ -0,0 +1,87 @@
+package loadshed
+
+import "testing"
+
+func TestLimiterPriorityShare(t *testing.T) {
+	tests := []struct {
+		priority Priority
+		want     int
+	}{
+		{priority: PriorityCritical, want: 10},
+		{priority: PriorityNormal, want: 9},
+		{priority: PriorityLow, want: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.priority.String(), func(t *testing.T) {
+			limiter := NewLimiter("test", StaticLimit(10))
+			acquired := 0
+
+			for range 20 {
+				if _, ok := limiter.Acquire(tt.priority); ok {
+					acquired++
+				}
+			}
+
+			if acquired != tt.want {
+				t.Fatalf("acquired = %d, want %d", acquired, tt.want)
+			}
+		})
+	}
+}
+
+func TestLimiterRelease(t *testing.T) {
+	limiter := NewLimiter("test", NewAimdLimit(2, 1, 10, 0))
+
+	release, ok := limiter.Acquire(PriorityCritical)
+
+	if !ok {
+		t.Fatal("first request rejected")
+	}
+
+	if _, ok := limiter.Acquire(PriorityCritical); !ok {
+		t.Fatal("second request rejected")
+	}
+
+	if _, ok := limiter.Acquire(PriorityCritical); ok {
+		t.Fatal("request over limit accepted")
+	}
+
+	release(true)
+
+	if got := limiter.GetInFlight(); got != 1 {
+		t.Fatalf("in flight = %d, want 1", got)
+	}
+
+	// dropped уменьшает AIMD лимит: 2 * 0.9
+	if got := limiter.GetLimit(); got != 1 {
+		t.Fatalf("limit = %d, want 1", got)
+	}
+
+	if _, ok := limiter.Acquire(PriorityCritical); ok {
+		t.Fatal("request over decreased limit accepted")
+	}
+}
+
+func TestLimiterRoutePriority(t *testing.T) {
+	limiter := NewLimiter("test", StaticLimit(10))
+	limiter.SetRoutePriority("/v1/reports", PriorityLow)
+	limiter.SetRoutePriority("/v1/reports/daily", PriorityCritical)
+
+	tests := []struct {
+		route string
+		want  Priority
+	}{
+		{route: "/v1/reports/export", want: PriorityLow},
+		{route: "/v1/reports/daily/:id", want: PriorityCritical},
+		{route: "/v1/orders", want: PriorityNormal},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			if got := limiter.GetRoutePriority(tt.route); got != tt.want {
+				t.Fatalf("priority = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
@@ -1,20 +1,49 @@
 package metrics
 
 import (
+	"errors"
 	"github.com/prometheus/client_golang/prometheus"
+	"net/http"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"unicode/utf8"
 )
 
 const (
-	MetricNameHttpRequest = "http_request_metrics_info"
-	MetricLabelHttpStatus = "status"
-	MetricLabelHttpMethod = "method"
-	MetricLabelHttpUrl    = "url"
+	MetricNameHttpRequest         = "http_request_metrics_info"
+	MetricNameHttpRequestInFlight = "http_requests_in_flight"
+	MetricNameHttpRequestSize     = "http_request_size_bytes"
+	MetricNameHttpResponseSize    = "http_response_size_bytes"
+	MetricNameHttpPanics          = "http_panics_recovered_total"
+	MetricNameHttpTimeouts        = "http_handler_timeouts_total"
+	MetricNameHttpClientBreaker   = "http_client_circuit_breaker_state"
+	MetricNameHttpApiClient       = "http_api_client_requests_total"
+	MetricNameHttpConcurrency     = "http_concurrency_limit"
+	MetricNameHttpShed            = "http_requests_shed_total"
+	MetricLabelHttpStatus         = "status"
+	MetricLabelHttpMethod         = "method"
+	MetricLabelHttpUrl            = "url"
+	MetricLabelRouteGroup         = "route_group"
+	MetricLabelHost               = "host"
+	MetricLabelClient             = "client"
+	MetricLabelLimiter            = "limiter"
+	MetricLabelPriority           = "priority"
+	ExemplarLabelRequestId        = "request_id"
+	ExemplarLabelTraceId          = "trace_id"
 )
 
-func NewCollector(serviceName string) *Collector {
-	m := &Collector{serviceName: serviceName}
+// DefaultRouteGroup группа роутов, для которых не заданы свои бакеты
+const DefaultRouteGroup = "default"
+
+// DefaultSizeBuckets бакеты для размеров запроса и ответа: от 100 байт до ~100 MiB
+var DefaultSizeBuckets = prometheus.ExponentialBuckets(100, 4, 10)
+
+// NewCollector создает сборщик HTTP метрик и регистрирует их в переданном registry
+func NewCollector(serviceName string, registry *prometheus.Registry) *Collector {
+	m := &Collector{serviceName: serviceName, registry: registry}
 	m.init()
 	m.register()
 
@@ -23,17 +52,126 @@ func NewCollector(serviceName string) *Collector {
 
 type Collector struct {
 	serviceName        string
+	registry           *prometheus.Registry
 	httpRequestMetrics *prometheus.HistogramVec
+	httpInFlight       *prometheus.GaugeVec
+	httpRequestSize    *prometheus.HistogramVec
+	httpResponseSize   *prometheus.HistogramVec
+	httpPanics         *prometheus.CounterVec
+	httpTimeouts       *prometheus.CounterVec
+	httpClientBreakers *prometheus.GaugeVec
+	httpApiClients     *prometheus.CounterVec
+	httpConcurrency    *prometheus.GaugeVec
+	httpShed           *prometheus.CounterVec
 	once               sync.Once
+	exemplars          atomic.Bool
+	// defaultRegistryIncluded отдавать в /metrics и prometheus.DefaultGatherer
+	defaultRegistryIncluded atomic.Bool
+
+	groupsMu    sync.RWMutex
+	routeGroups []*routeGroup
+}
+
+// routeGroup группа роутов (по префиксу шаблона пути) со своими бакетами гистограммы времени ответа
+type routeGroup struct {
+	prefix  string
+	metrics *prometheus.HistogramVec
 }
 
 func (m *Collector) init() {
-	m.httpRequestMetrics = prometheus.NewHistogramVec(
+	m.httpRequestMetrics = m.newDurationHistogram(DefaultRouteGroup, prometheus.DefBuckets)
+
+	m.httpInFlight = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        MetricNameHttpRequestInFlight,
+			Help:        "Number of HTTP requests currently being served.",
+			ConstLabels: prometheus.Labels{"service": m.serviceName},
+		},
+		[]string{MetricLabelHttpMethod, MetricLabelHttpUrl},
+	)
+
+	m.httpRequestSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:        MetricNameHttpRequestSize,
+			Help:        "Histogram of HTTP request body size in bytes.",
+			Buckets:     DefaultSizeBuckets,
+			ConstLabels: prometheus.Labels{"service": m.serviceName},
+		},
+		[]string{MetricLabelHttpMethod, MetricLabelHttpUrl},
+	)
+
+	m.httpResponseSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:        MetricNameHttpResponseSize,
+			Help:        "Histogram of HTTP response body size in bytes.",
+			Buckets:     DefaultSizeBuckets,
+			ConstLabels: prometheus.Labels{"service": m.serviceName},
+		},
+		[]string{MetricLabelHttpStatus, MetricLabelHttpMethod, MetricLabelHttpUrl},
+	)
+
+	m.httpPanics = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        MetricNameHttpPanics,
+			Help:        "Number of panics recovered in HTTP handlers.",
+			ConstLabels: prometheus.Labels{"service": m.serviceName},
+		},
+		[]string{MetricLabelHttpMethod, MetricLabelHttpUrl},
+	)
+
+	m.httpTimeouts = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        MetricNameHttpTimeouts,
+			Help:        "Number of HTTP handlers that exceeded the handler timeout.",
+			ConstLabels: prometheus.Labels{"service": m.serviceName},
+		},
+		[]string{MetricLabelHttpMethod, MetricLabelHttpUrl},
+	)
+
+	m.httpClientBreakers = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        MetricNameHttpClientBreaker,
+			Help:        "State of outbound HTTP circuit breaker per host: 0 - closed, 1 - half-open, 2 - open.",
+			ConstLabels: prometheus.Labels{"service": m.serviceName},
+		},
+		[]string{MetricLabelHost},
+	)
+
+	m.httpApiClients = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        MetricNameHttpApiClient,
+			Help:        "Number of HTTP requests per API key client.",
+			ConstLabels: prometheus.Labels{"service": m.serviceName},
+		},
+		[]string{MetricLabelClient, MetricLabelHttpStatus, MetricLabelHttpMethod, MetricLabelHttpUrl},
+	)
+
+	m.httpConcurrency = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name:        MetricNameHttpConcurrency,
+			Help:        "Current concurrency limit of HTTP requests per limiter.",
+			ConstLabels: prometheus.Labels{"service": m.serviceName},
+		},
+		[]string{MetricLabelLimiter},
+	)
+
+	m.httpShed = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name:        MetricNameHttpShed,
+			Help:        "Number of HTTP requests rejected by concurrency limiter.",
+			ConstLabels: prometheus.Labels{"service": m.serviceName},
+		},
+		[]string{MetricLabelLimiter, MetricLabelPriority, MetricLabelHttpMethod, MetricLabelHttpUrl},
+	)
+}
+
+func (m *Collector) newDurationHistogram(group string, buckets []float64) *prometheus.HistogramVec {
+	return prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
 			Name:        MetricNameHttpRequest,
 			Help:        "Histogram of response time for handler in seconds.",
-			Buckets:     prometheus.DefBuckets,
-			ConstLabels: prometheus.Labels{"service": m.serviceName},
+			Buckets:     buckets,
+			ConstLabels: prometheus.Labels{"service": m.serviceName, MetricLabelRouteGroup: group},
 		},
 		[]string{MetricLabelHttpStatus, MetricLabelHttpMethod, MetricLabelHttpUrl},
 	)
@@ -41,12 +179,208 @@ func (m *Collector) init() {
 
 func (m *Collector) register() {
 	m.once.Do(func() {
-		prometheus.MustRegister(m.httpRequestMetrics)
+		m.registry.MustRegister(
+			m.httpRequestMetrics,
+			m.httpInFlight,
+			m.httpRequestSize,
+			m.httpResponseSize,
+			m.httpPanics,
+			m.httpTimeouts,
+			m.httpClientBreakers,
+			m.httpApiClients,
+			m.httpConcurrency,
+			m.httpShed,
+		)
 	})
 }
 
+// Registry возвращает registry, в котором зарегистрированы метрики
+func (m *Collector) Registry() *prometheus.Registry {
+	return m.registry
+}
+
+// Register регистрирует метрики сервиса в registry kernel
+func (m *Collector) Register(cs ...prometheus.Collector) error {
+	for _, c := range cs {
+		if err := m.registry.Register(c); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Handler http.Handler для эндпойнта /metrics: метрики registry kernel, а если включено - и prometheus.DefaultGatherer.
+// При включенных exemplars отдает метрики в формате OpenMetrics, если его запрашивает прометей
+func (m *Collector) Handler() http.Handler {
+	var gatherer prometheus.Gatherer = m.registry
+
+	if m.IsDefaultRegistryIncluded() {
+		gatherer = NewGatherer(m.registry)
+	}
+
+	if m.IsExemplarsEnabled() {
+		return NewOpenMetricsHandler(gatherer, m.registry)
+	}
+
+	return NewHandler(gatherer, m.registry)
+}
+
+// SetDefaultRegistryIncluded отдавать в /metrics и метрики глобального prometheus.DefaultRegisterer
+func (m *Collector) SetDefaultRegistryIncluded(included bool) {
+	m.defaultRegistryIncluded.Store(included)
+}
+
+// IsDefaultRegistryIncluded отдаются ли в /metrics метрики глобального prometheus.DefaultRegisterer
+func (m *Collector) IsDefaultRegistryIncluded() bool {
+	return m.defaultRegistryIncluded.Load()
+}
+
+// SetExemplarsEnabled включает привязку exemplars (request id, trace id) к бакетам гистограммы времени ответа
+func (m *Collector) SetExemplarsEnabled(enabled bool) {
+	m.exemplars.Store(enabled)
+}
+
+// IsExemplarsEnabled включены ли exemplars
+func (m *Collector) IsExemplarsEnabled() bool {
+	return m.exemplars.Load()
+}
+
+// SetRouteGroupBuckets задает бакеты гистограммы времени ответа для роутов, шаблон пути которых начинается с prefix
+// (например, "/api/v1/files" для загрузки файлов). Если роут подходит под несколько групп, берется самый длинный префикс
+func (m *Collector) SetRouteGroupBuckets(prefix string, buckets []float64) error {
+	if prefix == "" {
+		return errors.New("route group prefix is empty")
+	}
+
+	if len(buckets) == 0 {
+		return errors.New("route group buckets are empty")
+	}
+
+	m.groupsMu.Lock()
+	defer m.groupsMu.Unlock()
+
+	for _, g := range m.routeGroups {
+		if g.prefix == prefix {
+			return errors.New("route group already configured: " + prefix)
+		}
+	}
+
+	histogram := m.newDurationHistogram(prefix, buckets)
+
+	if err := m.registry.Register(histogram); err != nil {
+		return err
+	}
+
+	m.routeGroups = append(m.routeGroups, &routeGroup{prefix: prefix, metrics: histogram})
+
+	sort.Slice(m.routeGroups, func(i, j int) bool {
+		return len(m.routeGroups[i].prefix) > len(m.routeGroups[j].prefix)
+	})
+
+	return nil
+}
+
 func (m *Collector) GetMetrics(statusCode int, method string, path string, duration float64) {
-	m.httpRequestMetrics.
+	m.durationHistogram(path).
 		WithLabelValues(strconv.Itoa(statusCode), method, path).
 		Observe(duration)
 }
+
+// GetMetricsWithExemplar то же, что GetMetrics, но с exemplar (например, request id и trace id),
+// по которому из бакета в Grafana можно перейти к логам и событию в Sentry конкретного запроса.
+// Если exemplars выключены, labels пустые или превышают ограничения Prometheus - обычное наблюдение
+func (m *Collector) GetMetricsWithExemplar(statusCode int, method string, path string, duration float64, exemplar prometheus.Labels) {
+	observer := m.durationHistogram(path).WithLabelValues(strconv.Itoa(statusCode), method, path)
+
+	if len(exemplar) > 0 && m.IsExemplarsEnabled() && isValidExemplar(exemplar) {
+		if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok {
+			exemplarObserver.ObserveWithExemplar(duration, exemplar)
+
+			return
+		}
+	}
+
+	observer.Observe(duration)
+}
+
+// isValidExemplar проверяет ограничения exemplar, при нарушении которых ObserveWithExemplar паникует:
+// не больше ExemplarMaxRunes символов в именах и значениях, валидный UTF-8
+func isValidExemplar(exemplar prometheus.Labels) bool {
+	runes := 0
+
+	for name, value := range exemplar {
+		if !utf8.ValidString(name) || !utf8.ValidString(value) {
+			return false
+		}
+
+		runes += utf8.RuneCountInString(name) + utf8.RuneCountInString(value)
+	}
+
+	return runes <= prometheus.ExemplarMaxRunes
+}
+
+// ObserveSizes записывает размеры тела запроса и ответа
+func (m *Collector) ObserveSizes(statusCode int, method string, path string, requestSize int64, responseSize int64) {
+	if requestSize >= 0 {
+		m.httpRequestSize.WithLabelValues(method, path).Observe(float64(requestSize))
+	}
+
+	if responseSize >= 0 {
+		m.httpResponseSize.WithLabelValues(strconv.Itoa(statusCode), method, path).Observe(float64(responseSize))
+	}
+}
+
+// IncPanics увеличивает счетчик перехваченных паник
+func (m *Collector) IncPanics(method string, path string) {
+	m.httpPanics.WithLabelValues(method, path).Inc()
+}
+
+// IncTimeouts увеличивает счетчик хендлеров, не уложившихся в HANDLER_TIMEOUT
+func (m *Collector) IncTimeouts(method string, path string) {
+	m.httpTimeouts.WithLabelValues(method, path).Inc()
+}
+
+// IncInFlight увеличивает счетчик запросов в обработке
+func (m *Collector) IncInFlight(method string, path string) {
+	m.httpInFlight.WithLabelValues(method, path).Inc()
+}
+
+// DecInFlight уменьшает счетчик запросов в обработке
+func (m *Collector) DecInFlight(method string, path string) {
+	m.httpInFlight.WithLabelValues(method, path).Dec()
+}
+
+// SetCircuitBreakerState выставляет состояние circuit breaker исходящих запросов к хосту
+func (m *Collector) SetCircuitBreakerState(host string, state float64) {
+	m.httpClientBreakers.WithLabelValues(host).Set(state)
+}
+
+// IncApiClientRequests увеличивает счетчик запросов клиента API ключа
+func (m *Collector) IncApiClientRequests(client string, statusCode int, method string, path string) {
+	m.httpApiClients.WithLabelValues(client, strconv.Itoa(statusCode), method, path).Inc()
+}
+
+// SetConcurrencyLimit выставляет текущий лимит одновременных запросов
+func (m *Collector) SetConcurrencyLimit(limiter string, limit float64) {
+	m.httpConcurrency.WithLabelValues(limiter).Set(limit)
+}
+
+// IncShedRequests увеличивает счетчик запросов, отклоненных ограничителем одновременных запросов
+func (m *Collector) IncShedRequests(limiter string, priority string, method string, path string) {
+	m.httpShed.WithLabelValues(limiter, priority, method, path).Inc()
+}
+
+// durationHistogram гистограмма времени ответа для группы, в которую входит роут
+func (m *Collector) durationHistogram(path string) *prometheus.HistogramVec {
+	m.groupsMu.RLock()
+	defer m.groupsMu.RUnlock()
+
+	for _, g := range m.routeGroups {
+		if strings.HasPrefix(path, g.prefix) {
+			return g.metrics
+		}
+	}
+
+	return m.httpRequestMetrics
+}
@@ -0,0 +1,68 @@
+package metrics
+
+import (
+	"errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	dto "github.com/prometheus/client_model/go"
+	"net/http"
+	"sort"
+)
+
+// NewRegistry создает registry прометея с метриками рантайма Go и процесса.
+// У каждого kernel свой registry, поэтому несколько kernel (например, в тестах) не конфликтуют
+func NewRegistry() *prometheus.Registry {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return registry
+}
+
+// NewGatherer метрики registry вместе с глобальным prometheus.DefaultGatherer: метрики, которые сторонние библиотеки
+// регистрируют в prometheus.DefaultRegisterer, тоже отдаются в /metrics (включается METRICS_INCLUDE_DEFAULT_REGISTRY).
+// Одноименные метрики берутся из registry (метрики рантайма есть в обоих)
+func NewGatherer(registry *prometheus.Registry) prometheus.Gatherer {
+	return prometheus.GathererFunc(func() ([]*dto.MetricFamily, error) {
+		families, err := registry.Gather()
+		names := make(map[string]struct{}, len(families))
+
+		for _, family := range families {
+			names[family.GetName()] = struct{}{}
+		}
+
+		// при ошибке Gather возвращает метрики, которые удалось собрать
+		defaultFamilies, defaultErr := prometheus.DefaultGatherer.Gather()
+
+		for _, family := range defaultFamilies {
+			if _, ok := names[family.GetName()]; !ok {
+				families = append(families, family)
+			}
+		}
+
+		sort.Slice(families, func(i, j int) bool {
+			return families[i].GetName() < families[j].GetName()
+		})
+
+		return families, errors.Join(err, defaultErr)
+	})
+}
+
+// NewHandler http.Handler, отдающий метрики gatherer (registry kernel или NewGatherer(registry)),
+// ошибки сбора учитываются в registry
+func NewHandler(gatherer prometheus.Gatherer, registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		Registry: registry,
+	})
+}
+
+// NewOpenMetricsHandler http.Handler, отдающий метрики gatherer с поддержкой формата OpenMetrics (нужен для exemplars)
+func NewOpenMetricsHandler(gatherer prometheus.Gatherer, registry *prometheus.Registry) http.Handler {
+	return promhttp.HandlerFor(gatherer, promhttp.HandlerOpts{
+		Registry:          registry,
+		EnableOpenMetrics: true,
+	})
+}
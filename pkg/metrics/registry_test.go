@@ -0,0 +1,57 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCollectorHandlerDefaultRegistry(t *testing.T) {
+	defaultCounter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_default_registry_total"})
+	prometheus.MustRegister(defaultCounter)
+	t.Cleanup(func() { prometheus.Unregister(defaultCounter) })
+
+	kernelCounter := prometheus.NewCounter(prometheus.CounterOpts{Name: "test_kernel_registry_total"})
+	defaultCounter.Inc()
+	kernelCounter.Inc()
+
+	tests := []struct {
+		name        string
+		included    bool
+		wantDefault bool
+	}{
+		{name: "kernel registry only", included: false, wantDefault: false},
+		{name: "with default registry", included: true, wantDefault: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			collector := NewCollector("test", NewRegistry())
+			collector.SetDefaultRegistryIncluded(tt.included)
+
+			if err := collector.Register(kernelCounter); err != nil {
+				t.Fatal(err)
+			}
+
+			recorder := httptest.NewRecorder()
+			collector.Handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+			body := recorder.Body.String()
+
+			if !strings.Contains(body, "test_kernel_registry_total 1") {
+				t.Fatalf("metrics without kernel registry counter:\n%s", body)
+			}
+
+			if got := strings.Contains(body, "test_default_registry_total 1"); got != tt.wantDefault {
+				t.Fatalf("default registry counter in metrics = %v, want %v", got, tt.wantDefault)
+			}
+
+			// метрики рантайма есть в обоих registry и не дублируются
+			if count := strings.Count(body, "# TYPE go_goroutines "); count != 1 {
+				t.Fatalf("go_goroutines families = %d, want 1", count)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/exgamer/gosdk-core/pkg/app"
+	"github.com/exgamer/gosdk-http-core/pkg/apikey"
+	"github.com/exgamer/gosdk-http-core/pkg/di"
+	gin2 "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"github.com/exgamer/gosdk-http-core/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// ApiKeyClientUnauthorized значение label client в метриках для запросов с отсутствующим или неверным ключом
+const ApiKeyClientUnauthorized = "__unauthorized__"
+
+// ApiKeyMiddleware аутентифицирует клиента по API ключу через authenticator (di.GetApiKeyAuthenticator),
+// кладет его id в HttpInfo.ApiClientId и считает запросы клиента в метрике http_api_client_requests_total.
+// Запрос без ключа или с неверным ключом отклоняется с 401
+func ApiKeyMiddleware(a *app.App, authenticator *apikey.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if authenticator == nil {
+			response.AbortWithError(c, http.StatusInternalServerError, "", errors.New("api key authenticator is not configured"), nil)
+
+			return
+		}
+
+		clientId := ApiKeyClientUnauthorized
+
+		if metricsCollector, err := di.GetMetricsCollector(a.Container); err == nil && metricsCollector != nil {
+			path := gin2.GetRoutePath(c)
+
+			defer func() {
+				metricsCollector.IncApiClientRequests(clientId, c.Writer.Status(), c.Request.Method, path)
+			}()
+		}
+
+		key := c.GetHeader(authenticator.GetHeader())
+
+		if key == "" && authenticator.QueryParam != "" {
+			key = c.Query(authenticator.QueryParam)
+		}
+
+		client, err := authenticator.Authenticate(c.Request.Context(), key)
+
+		if err != nil {
+			if errors.Is(err, apikey.ErrKeyMissing) || errors.Is(err, apikey.ErrKeyInvalid) {
+				response.AbortWithError(c, http.StatusUnauthorized, "", err, nil)
+
+				return
+			}
+
+			response.AbortWithError(c, http.StatusInternalServerError, "", err, nil)
+
+			return
+		}
+
+		clientId = client.Id
+
+		if httpInfo := gin2.GetHttpInfoFromContext(c.Request.Context()); httpInfo != nil {
+			httpInfo.ApiClientId = client.Id
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,172 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/exgamer/gosdk-http-core/pkg/response"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"reflect"
+	"runtime"
+)
+
+// ctxKeyBodyLimit лимит тела запроса, общий для всех оберток тела, чтобы лимит роута заменял глобальный, а не сужал его
+const ctxKeyBodyLimit = "body_limit_body"
+
+// ErrBodyTooLarge тело запроса больше лимита
+var ErrBodyTooLarge = errors.New("request body is too large")
+
+// bodyLimitHandlerName имя хендлера BodyLimitMiddleware в цепочке роута
+var bodyLimitHandlerName string
+
+func init() {
+	bodyLimitHandlerName = runtime.FuncForPC(reflect.ValueOf(BodyLimitMiddleware(0)).Pointer()).Name()
+}
+
+// BodyLimitMiddleware ограничивает размер тела запроса limit байтами (limit <= 0 - без ограничения).
+// Kernel подключает его глобально с MAX_REQUEST_BODY_SIZE, на роуте можно задать свой лимит - он заменяет глобальный.
+// Лимит общий для всех оберток тела (DecompressionMiddleware, WebhookSignatureMiddleware, LoggerMiddleware),
+// повторное подключение только меняет его. Запрос с Content-Length больше лимита последнего BodyLimitMiddleware
+// в цепочке сразу отклоняется с 413, чтение сверх лимита возвращает *http.MaxBytesError,
+// который validators.ValidateRequestBody отдает как 413. В обоих случаях соединение закрывается после ответа
+//
+// noinline: при встраивании имя хендлера меняется, и isLast не найдет его в цепочке
+//
+//go:noinline
+func BodyLimitMiddleware(limit int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var shared *bodyLimit
+
+		if current, ok := c.Get(ctxKeyBodyLimit); ok {
+			shared = current.(*bodyLimit)
+		} else {
+			if c.Request.Body == nil || c.Request.Body == http.NoBody {
+				c.Next()
+
+				return
+			}
+
+			shared = &bodyLimit{writer: c.Writer, contentLength: c.Request.ContentLength}
+			c.Request.Body = &limitedBody{ReadCloser: c.Request.Body, bodyLimit: shared, contentLength: c.Request.ContentLength}
+			c.Set(ctxKeyBodyLimit, shared)
+		}
+
+		shared.limit = limit
+		shared.applied++
+
+		if limit > 0 && shared.contentLength > limit && shared.isLast(c) {
+			shared.closeConnection()
+			response.AbortWithError(c, http.StatusRequestEntityTooLarge, "", ErrBodyTooLarge, map[string]any{"limit": limit})
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// wrapWithBodyLimit оборачивает подмененное тело запроса (распакованное, прочитанное заранее) в лимит BodyLimitMiddleware,
+// чтобы лимит роута действовал и на него. Без BodyLimitMiddleware тело возвращается как есть
+func wrapWithBodyLimit(c *gin.Context, body io.ReadCloser, contentLength int64) io.ReadCloser {
+	current, ok := c.Get(ctxKeyBodyLimit)
+
+	if !ok {
+		return body
+	}
+
+	return &limitedBody{ReadCloser: body, bodyLimit: current.(*bodyLimit), contentLength: contentLength}
+}
+
+// bodyLimit лимит тела запроса, заданный последним пройденным BodyLimitMiddleware
+type bodyLimit struct {
+	limit  int64
+	writer gin.ResponseWriter
+	// contentLength заявленный размер тела до подмены (например, сжатого до распаковки)
+	contentLength int64
+	// applied сколько BodyLimitMiddleware уже пройдено
+	applied int
+}
+
+// isLast последний ли это BodyLimitMiddleware в цепочке: лимит роута может быть больше глобального
+func (l *bodyLimit) isLast(c *gin.Context) bool {
+	total := 0
+
+	for _, name := range c.HandlerNames() {
+		if name == bodyLimitHandlerName {
+			total++
+		}
+	}
+
+	return l.applied >= total
+}
+
+// closeConnection как http.MaxBytesReader: соединение закрывается после ответа, недочитанное тело не вычитывается
+func (l *bodyLimit) closeConnection() {
+	if !l.writer.Written() {
+		l.writer.Header().Set("Connection", "close")
+	}
+}
+
+func (l *bodyLimit) exceeded() error {
+	l.closeConnection()
+
+	return &http.MaxBytesError{Limit: l.limit}
+}
+
+// limitedBody тело запроса с общим изменяемым лимитом. В отличие от http.MaxBytesReader не теряет данные
+// при превышении лимита: если лимит потом увеличат, чтение продолжится с того же места
+type limitedBody struct {
+	io.ReadCloser
+	bodyLimit     *bodyLimit
+	contentLength int64
+	read          int64
+	// pending байт, прочитанный для проверки, что тело длиннее лимита
+	pending []byte
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	if limit := b.bodyLimit.limit; limit > 0 {
+		// заявленный размер больше лимита: отказ без чтения
+		if b.contentLength > limit {
+			return 0, b.bodyLimit.exceeded()
+		}
+
+		remaining := limit - b.read
+
+		if remaining <= 0 {
+			if len(b.pending) == 0 {
+				buf := make([]byte, 1)
+				n, err := b.ReadCloser.Read(buf)
+
+				if n == 0 {
+					return 0, err
+				}
+
+				b.pending = buf[:n]
+			}
+
+			return 0, b.bodyLimit.exceeded()
+		}
+
+		if int64(len(p)) > remaining {
+			p = p[:remaining]
+		}
+	}
+
+	if len(b.pending) > 0 {
+		n := copy(p, b.pending)
+		b.pending = b.pending[n:]
+		b.read += int64(n)
+
+		return n, nil
+	}
+
+	n, err := b.ReadCloser.Read(p)
+	b.read += int64(n)
+
+	return n, err
+}
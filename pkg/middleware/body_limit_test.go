@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestMain(m *testing.M) {
+	gin.SetMode(gin.TestMode)
+	os.Exit(m.Run())
+}
+
+// readBodyHandler читает тело целиком и отдает его размер, 413 - если тело больше лимита
+func readBodyHandler(c *gin.Context) {
+	body, err := io.ReadAll(c.Request.Body)
+
+	var maxBytesError *http.MaxBytesError
+	if errors.As(err, &maxBytesError) {
+		c.String(http.StatusRequestEntityTooLarge, strconv.FormatInt(maxBytesError.Limit, 10))
+
+		return
+	}
+
+	if err != nil {
+		c.String(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	c.String(http.StatusOK, strconv.Itoa(len(body)))
+}
+
+func newBodyRequest(size int, chunked bool) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(strings.Repeat("a", size)))
+
+	if chunked {
+		request.ContentLength = -1
+	}
+
+	return request
+}
+
+func TestBodyLimitMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		globalLimit    int64
+		routeLimit     *int64
+		size           int
+		chunked        bool
+		wantStatus     int
+		wantBody       string
+		wantConnection string
+		wantHandler    bool
+	}{
+		{name: "within limit", globalLimit: 10, size: 10, wantStatus: http.StatusOK, wantBody: "10", wantHandler: true},
+		{name: "content length over limit", globalLimit: 10, size: 11, wantStatus: http.StatusRequestEntityTooLarge, wantConnection: "close"},
+		{name: "chunked over limit", globalLimit: 10, size: 11, chunked: true, wantStatus: http.StatusRequestEntityTooLarge, wantBody: "10", wantConnection: "close", wantHandler: true},
+		{name: "route raises limit", globalLimit: 10, routeLimit: ptr[int64](100), size: 50, wantStatus: http.StatusOK, wantBody: "50", wantHandler: true},
+		{name: "route lowers limit", globalLimit: 100, routeLimit: ptr[int64](10), size: 50, wantStatus: http.StatusRequestEntityTooLarge, wantConnection: "close"},
+		{name: "route lowers limit for chunked body", globalLimit: 100, routeLimit: ptr[int64](10), size: 50, chunked: true, wantStatus: http.StatusRequestEntityTooLarge, wantBody: "10", wantConnection: "close", wantHandler: true},
+		{name: "route disables limit", globalLimit: 10, routeLimit: ptr[int64](0), size: 50, wantStatus: http.StatusOK, wantBody: "50", wantHandler: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handlerCalled := false
+			handlers := []gin.HandlerFunc{func(c *gin.Context) {
+				handlerCalled = true
+				readBodyHandler(c)
+			}}
+
+			if tt.routeLimit != nil {
+				handlers = append([]gin.HandlerFunc{BodyLimitMiddleware(*tt.routeLimit)}, handlers...)
+			}
+
+			router := gin.New()
+			router.Use(BodyLimitMiddleware(tt.globalLimit))
+			router.POST("/upload", handlers...)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, newBodyRequest(tt.size, tt.chunked))
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", recorder.Code, tt.wantStatus)
+			}
+
+			if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+				t.Fatalf("body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+
+			if got := recorder.Header().Get("Connection"); got != tt.wantConnection {
+				t.Fatalf("Connection = %q, want %q", got, tt.wantConnection)
+			}
+
+			if handlerCalled != tt.wantHandler {
+				t.Fatalf("handler called = %v, want %v", handlerCalled, tt.wantHandler)
+			}
+		})
+	}
+}
+
+func TestBodyLimitMiddlewareRejectsWithEnvelope(t *testing.T) {
+	router := gin.New()
+	router.Use(BodyLimitMiddleware(10))
+	router.POST("/upload", readBodyHandler)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, newBodyRequest(11, false))
+
+	if body := recorder.Body.String(); !strings.Contains(body, `"error":"payload_too_large"`) || !strings.Contains(body, `"limit":10`) {
+		t.Fatalf("body = %s, want payload_too_large envelope with limit", body)
+	}
+}
+
+func ptr[T any](value T) *T {
+	return &value
+}
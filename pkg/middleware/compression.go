@@ -0,0 +1,55 @@
+package middleware
+
+import (
+	"github.com/exgamer/gosdk-http-core/pkg/compression"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// CompressionMiddleware сжимает ответ кодировкой из Accept-Encoding (zstd, br, gzip).
+// Сжимаются ответы не меньше MinSize с Content-Type из списка, encoders берутся из пула.
+// Подключается раньше FormattedResponseMiddleware, чтобы сжимался и ответ, который тот формирует после хендлера
+func CompressionMiddleware(options compression.Options) gin.HandlerFunc {
+	encodings := options.GetEncodings()
+
+	return func(c *gin.Context) {
+		// ответ зависит от Accept-Encoding, даже если конкретный ответ не сжат
+		addVary(c.Writer.Header(), "Accept-Encoding")
+
+		encoding := compression.Negotiate(c.GetHeader("Accept-Encoding"), encodings)
+
+		if encoding == "" || c.Request.Method == http.MethodHead || c.GetHeader("Upgrade") != "" {
+			c.Next()
+
+			return
+		}
+
+		original := c.Writer
+		writer := compression.NewWriter(original, encoding, options)
+		c.Writer = writer
+
+		defer func() {
+			if err := writer.Close(); err != nil {
+				_ = c.Error(err)
+			}
+
+			c.Writer = original
+		}()
+
+		c.Next()
+	}
+}
+
+func addVary(header http.Header, value string) {
+	for _, existing := range header.Values("Vary") {
+		if slices.ContainsFunc(strings.Split(existing, ","), func(v string) bool {
+			return strings.EqualFold(strings.TrimSpace(v), value)
+		}) {
+			return
+		}
+	}
+
+	header.Add("Vary", value)
+}
@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/exgamer/gosdk-core/pkg/app"
+	"github.com/exgamer/gosdk-http-core/pkg/di"
+	gin2 "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"github.com/exgamer/gosdk-http-core/pkg/loadshed"
+	"github.com/exgamer/gosdk-http-core/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+// ErrOverloaded запрос отклонен из-за перегрузки сервиса
+var ErrOverloaded = errors.New("service is overloaded, retry later")
+
+// ConcurrencyLimitMiddleware ограничивает число одновременно обрабатываемых запросов (load shedding).
+// Запросы сверх лимита с учетом приоритета роута сразу отклоняются с 503 и Retry-After,
+// лимит и отклоненные запросы публикуются в метриках http_concurrency_limit и http_requests_shed_total
+func ConcurrencyLimitMiddleware(a *app.App, limiter *loadshed.Limiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		path := gin2.GetRoutePath(c)
+
+		priority := limiter.GetRoutePriority(path)
+		release, ok := limiter.Acquire(priority)
+		// без collector в DI работаем без метрик
+		metricsCollector, _ := di.GetMetricsCollector(a.Container)
+
+		if !ok {
+			if metricsCollector != nil {
+				metricsCollector.IncShedRequests(limiter.Name, priority.String(), c.Request.Method, path)
+			}
+
+			retryAfter := durationSeconds(limiter.RetryAfter)
+			c.Header("Retry-After", strconv.Itoa(retryAfter))
+			response.AbortWithError(c, http.StatusServiceUnavailable, "", ErrOverloaded, map[string]any{
+				"priority":    priority.String(),
+				"retry_after": retryAfter,
+			})
+
+			return
+		}
+
+		defer func() {
+			// паника хендлера проходит здесь раньше, чем CustomRecovery отдаст 500: статус еще 200,
+			// поэтому считаем ее ошибкой сами и пробрасываем дальше
+			recovered := recover()
+			release(recovered != nil || c.Writer.Status() >= http.StatusInternalServerError || errors.Is(c.Request.Context().Err(), context.DeadlineExceeded))
+
+			if metricsCollector != nil {
+				metricsCollector.SetConcurrencyLimit(limiter.Name, float64(limiter.GetLimit()))
+			}
+
+			if recovered != nil {
+				panic(recovered)
+			}
+		}()
+
+		c.Next()
+	}
+}
@@ -0,0 +1,145 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/exgamer/gosdk-http-core/pkg/compression"
+	"github.com/exgamer/gosdk-http-core/pkg/config"
+	"github.com/exgamer/gosdk-http-core/pkg/constants"
+	"github.com/exgamer/gosdk-http-core/pkg/response"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// maxContentEncodings сколько кодировок подряд можно применить к телу запроса
+const maxContentEncodings = 2
+
+var (
+	ErrUnsupportedContentEncoding = errors.New("unsupported request content encoding")
+	ErrInvalidCompressedBody      = errors.New("request body is not correctly compressed")
+)
+
+// DecompressionMiddleware распаковывает тело запроса с Content-Encoding gzip, deflate, br или zstd,
+// поэтому LoggerMiddleware и validators.ValidateRequestBody получают обычное тело.
+// Распакованное тело ограничено лимитом BodyLimitMiddleware (MAX_REQUEST_BODY_SIZE или лимит роута) - защита
+// от zip бомб, maxSize > 0 - дополнительный лимит распакованного тела. Без BodyLimitMiddleware распакованное тело
+// ограничено maxSize (0 - config.DefaultMaxRequestBodySize). Чтение сверх лимита возвращает *http.MaxBytesError,
+// и validators.ValidateRequestBody отдает 413. Неподдерживаемая кодировка - 415. Подключается раньше LoggerMiddleware
+func DecompressionMiddleware(maxSize int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		encodings := parseContentEncoding(c.GetHeader("Content-Encoding"))
+
+		if len(encodings) == 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+
+			return
+		}
+
+		if len(encodings) > maxContentEncodings || slices.ContainsFunc(encodings, func(encoding string) bool {
+			return !compression.IsSupported(encoding)
+		}) {
+			// 415 отдается и за неподдерживаемый Content-Type, поэтому тип ошибки уточняем
+			response.AbortWithError(c, http.StatusUnsupportedMediaType, constants.UnsupportedEncoding, ErrUnsupportedContentEncoding, map[string]any{
+				"content_encoding": c.GetHeader("Content-Encoding"),
+			})
+
+			return
+		}
+
+		originalBody := c.Request.Body
+		var reader io.Reader = originalBody
+		decoders := make([]io.Closer, 0, len(encodings))
+
+		defer func() {
+			for _, decoder := range decoders {
+				_ = decoder.Close()
+			}
+		}()
+
+		// кодировки перечислены в порядке применения, распаковываем с конца
+		for i := len(encodings) - 1; i >= 0; i-- {
+			decoder, err := compression.NewDecoder(encodings[i], reader)
+
+			if err != nil {
+				var maxBytesError *http.MaxBytesError
+
+				if errors.As(err, &maxBytesError) {
+					response.AbortWithError(c, http.StatusRequestEntityTooLarge, "", ErrBodyTooLarge, map[string]any{
+						"limit": maxBytesError.Limit,
+					})
+
+					return
+				}
+
+				response.AbortWithError(c, http.StatusBadRequest, "", ErrInvalidCompressedBody, nil)
+
+				return
+			}
+
+			decoders = append(decoders, decoder)
+			reader = decoder
+		}
+
+		// лимит BodyLimitMiddleware считает сжатые байты, поэтому распакованное тело ограничиваем им же
+		if _, ok := c.Get(ctxKeyBodyLimit); !ok || maxSize > 0 {
+			size := maxSize
+
+			if size <= 0 {
+				size = config.DefaultMaxRequestBodySize
+			}
+
+			reader = &decompressedLimitReader{reader: reader, remaining: size, limit: size}
+		}
+
+		c.Request.Body = wrapWithBodyLimit(c, readCloser{Reader: reader, Closer: originalBody}, -1)
+		c.Request.Header.Del("Content-Encoding")
+		c.Request.Header.Del("Content-Length")
+		c.Request.ContentLength = -1
+
+		c.Next()
+	}
+}
+
+// parseContentEncoding кодировки из Content-Encoding без identity
+func parseContentEncoding(value string) []string {
+	encodings := make([]string, 0, 1)
+
+	for _, encoding := range strings.Split(value, ",") {
+		encoding = strings.ToLower(strings.TrimSpace(encoding))
+
+		if encoding != "" && encoding != "identity" {
+			encodings = append(encodings, encoding)
+		}
+	}
+
+	return encodings
+}
+
+// decompressedLimitReader ограничивает размер распакованного тела
+type decompressedLimitReader struct {
+	reader    io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (r *decompressedLimitReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, &http.MaxBytesError{Limit: r.limit}
+	}
+
+	// читаем на байт больше лимита, чтобы отличить тело ровно в лимит от превышения
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+
+	if r.remaining < 0 {
+		return n + int(r.remaining), &http.MaxBytesError{Limit: r.limit}
+	}
+
+	return n, err
+}
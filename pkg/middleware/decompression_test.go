@@ -0,0 +1,101 @@
+package middleware
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func gzipBody(t *testing.T, body string) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	writer := gzip.NewWriter(&buf)
+
+	if _, err := writer.Write([]byte(body)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	return buf.Bytes()
+}
+
+func TestDecompressionMiddleware(t *testing.T) {
+	compressed := gzipBody(t, strings.Repeat("a", 1000))
+
+	tests := []struct {
+		name        string
+		globalLimit int64
+		routeLimit  int64
+		maxSize     int64
+		encoding    string
+		body        []byte
+		wantStatus  int
+		wantBody    string
+		wantError   string
+	}{
+		{name: "gzip", encoding: "gzip", body: compressed, wantStatus: http.StatusOK, wantBody: "1000"},
+		{name: "within body limit", globalLimit: 2000, encoding: "gzip", body: compressed, wantStatus: http.StatusOK, wantBody: "1000"},
+		{
+			// сжатое тело меньше лимита роута, распакованное - больше
+			name:        "decompressed over route limit",
+			globalLimit: 10 << 20,
+			routeLimit:  500,
+			encoding:    "gzip",
+			body:        compressed,
+			wantStatus:  http.StatusRequestEntityTooLarge,
+			wantBody:    "500",
+		},
+		{name: "decompressed over global limit", globalLimit: 500, encoding: "gzip", body: compressed, wantStatus: http.StatusRequestEntityTooLarge, wantBody: "500"},
+		{name: "decompressed over max size", globalLimit: 2000, maxSize: 100, encoding: "gzip", body: compressed, wantStatus: http.StatusRequestEntityTooLarge, wantBody: "100"},
+		{name: "max size without body limit", maxSize: 100, encoding: "gzip", body: compressed, wantStatus: http.StatusRequestEntityTooLarge, wantBody: "100"},
+		{name: "unsupported encoding", encoding: "compress", body: compressed, wantStatus: http.StatusUnsupportedMediaType, wantError: "unsupported_encoding"},
+		{name: "too many encodings", encoding: "gzip, gzip, gzip", body: compressed, wantStatus: http.StatusUnsupportedMediaType, wantError: "unsupported_encoding"},
+		{name: "invalid gzip", encoding: "gzip", body: []byte("not gzip"), wantStatus: http.StatusBadRequest, wantError: "incorrect_parameters"},
+		{name: "identity", encoding: "identity", body: []byte("plain"), wantStatus: http.StatusOK, wantBody: "5"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+
+			if tt.globalLimit > 0 {
+				router.Use(BodyLimitMiddleware(tt.globalLimit))
+			}
+
+			router.Use(DecompressionMiddleware(tt.maxSize))
+
+			if tt.routeLimit > 0 {
+				router.POST("/upload", BodyLimitMiddleware(tt.routeLimit), readBodyHandler)
+			} else {
+				router.POST("/upload", readBodyHandler)
+			}
+
+			request := httptest.NewRequest(http.MethodPost, "/upload", bytes.NewReader(tt.body))
+			request.Header.Set("Content-Encoding", tt.encoding)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", recorder.Code, tt.wantStatus, recorder.Body.String())
+			}
+
+			if tt.wantBody != "" && recorder.Body.String() != tt.wantBody {
+				t.Fatalf("body = %q, want %q", recorder.Body.String(), tt.wantBody)
+			}
+
+			if tt.wantError != "" && !strings.Contains(recorder.Body.String(), `"error":"`+tt.wantError+`"`) {
+				t.Fatalf("body = %s, want error %s", recorder.Body.String(), tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,98 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"github.com/exgamer/gosdk-http-core/pkg/authz"
+	gin2 "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"github.com/exgamer/gosdk-http-core/pkg/jwt"
+	"github.com/exgamer/gosdk-http-core/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"slices"
+)
+
+// RequirePermissions пропускает запрос, только если у субъекта есть все permissions (политика authz.GetPolicy())
+func RequirePermissions(permissions ...string) gin.HandlerFunc {
+	return Guard(nil, func(ctx context.Context, policy authz.Policy, subject *authz.Subject) (bool, map[string]any, error) {
+		allowed, err := policy.HasPermissions(ctx, subject, permissions)
+
+		return allowed, map[string]any{"permissions": permissions}, err
+	})
+}
+
+// RequireRoles пропускает запрос, только если у субъекта есть хотя бы одна из ролей
+func RequireRoles(roles ...string) gin.HandlerFunc {
+	return Guard(nil, func(ctx context.Context, policy authz.Policy, subject *authz.Subject) (bool, map[string]any, error) {
+		allowed, err := policy.HasRoles(ctx, subject, roles)
+
+		return allowed, map[string]any{"roles": roles}, err
+	})
+}
+
+// RequireCompanyAccess пропускает запрос, только если у субъекта есть доступ к компаниям
+// из заголовков Company-Id и Company-Ids. Запрос без этих заголовков отклоняется
+func RequireCompanyAccess() gin.HandlerFunc {
+	return Guard(nil, func(ctx context.Context, policy authz.Policy, subject *authz.Subject) (bool, map[string]any, error) {
+		companyIds := requestedCompanyIds(subject)
+
+		if len(companyIds) == 0 {
+			return false, map[string]any{"company_ids": "company is not specified"}, nil
+		}
+
+		allowed, err := policy.HasCompanyAccess(ctx, subject, companyIds)
+
+		return allowed, map[string]any{"company_ids": companyIds}, err
+	})
+}
+
+// GuardCheck проверка guard'а: разрешен ли запрос и details для ответа 403
+type GuardCheck func(ctx context.Context, policy authz.Policy, subject *authz.Subject) (bool, map[string]any, error)
+
+// Guard guard роута со своей проверкой. policy nil - authz.GetPolicy().
+// Запрещенный запрос отклоняется с 403, ошибка политики - с 500
+func Guard(policy authz.Policy, check GuardCheck) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		subject := &authz.Subject{
+			Claims:   jwt.GetClaimsFromContext(ctx),
+			HttpInfo: gin2.GetHttpInfoFromContext(ctx),
+		}
+
+		currentPolicy := policy
+
+		if currentPolicy == nil {
+			currentPolicy = authz.GetPolicy()
+		}
+
+		allowed, details, err := check(ctx, currentPolicy, subject)
+
+		if err != nil {
+			response.AbortWithError(c, http.StatusInternalServerError, "", err, nil)
+
+			return
+		}
+
+		if !allowed {
+			response.AbortWithError(c, http.StatusForbidden, "", errors.New("access denied"), details)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func requestedCompanyIds(subject *authz.Subject) []int {
+	if subject.HttpInfo == nil {
+		return nil
+	}
+
+	companyIds := slices.Clone(subject.HttpInfo.CompanyIds)
+
+	if subject.HttpInfo.CompanyId != 0 && !slices.Contains(companyIds, subject.HttpInfo.CompanyId) {
+		companyIds = append(companyIds, subject.HttpInfo.CompanyId)
+	}
+
+	return companyIds
+}
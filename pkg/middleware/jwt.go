@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/exgamer/gosdk-http-core/pkg/constants"
+	gin2 "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"github.com/exgamer/gosdk-http-core/pkg/jwt"
+	"github.com/exgamer/gosdk-http-core/pkg/response"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// JwtMiddleware проверяет bearer токен из заголовка Authorization и кладет его claims в context запроса
+// (jwt.GetClaimsFromContext). Запрос без токена или с невалидным токеном отклоняется с 401
+func JwtMiddleware(verifier *jwt.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token := ""
+
+		if httpInfo := gin2.GetHttpInfoFromContext(c.Request.Context()); httpInfo != nil {
+			token = httpInfo.AuthToken
+		} else {
+			token = gin2.GetBearerToken(c.GetHeader(constants.AuthorizationHeaderName))
+		}
+
+		claims, err := verifier.Verify(c.Request.Context(), token)
+
+		if errors.Is(err, jwt.ErrKeysUnavailable) {
+			response.AbortWithError(c, http.StatusInternalServerError, "", err, nil)
+
+			return
+		}
+
+		if err != nil {
+			c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+			response.AbortWithError(c, http.StatusUnauthorized, "", err, nil)
+
+			return
+		}
+
+		c.Request = c.Request.WithContext(jwt.WithClaims(c.Request.Context(), claims))
+
+		c.Next()
+	}
+}
@@ -28,8 +28,9 @@ func LoggerMiddleware() gin.HandlerFunc {
 
 		var requestBody []byte
 		if req.Body != nil {
+			// в лог попадает первый 1 MiB, хендлер получает тело целиком (и ошибку лимита размера, если она будет)
 			requestBody, _ = io.ReadAll(io.LimitReader(req.Body, 1<<20))
-			req.Body = io.NopCloser(bytes.NewBuffer(requestBody))
+			req.Body = readCloser{Reader: io.MultiReader(bytes.NewReader(requestBody), req.Body), Closer: req.Body}
 		}
 
 		c.Next()
@@ -82,6 +83,12 @@ func LoggerMiddleware() gin.HandlerFunc {
 	}
 }
 
+// readCloser тело запроса с подмененным Reader и исходным Closer
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
+
 // TODO вынести в настройку, чтобы можно было внедрять свое
 func sanitizeHeaders(h http.Header) http.Header {
 	c := h.Clone()
@@ -1,25 +1,64 @@
 package middleware
 
 import (
+	"context"
 	"errors"
 	"github.com/exgamer/gosdk-core/pkg/app"
+	"github.com/exgamer/gosdk-http-core/pkg/constants"
 	"github.com/exgamer/gosdk-http-core/pkg/di"
 	exception2 "github.com/exgamer/gosdk-http-core/pkg/exception"
+	gin2 "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"github.com/exgamer/gosdk-http-core/pkg/metrics"
+	"github.com/getsentry/sentry-go"
 	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+	"net/http"
 	"time"
+	"unicode/utf8"
 )
 
-// MetricsMiddleware - мидлвар для обработки HTTP запросов метрик
+// ctxKeyMetricsCollected флаг, что метрики запроса уже собираются (kernel подключает MetricsMiddleware сам)
+const ctxKeyMetricsCollected = "metrics_collected"
+
+// MetricsMiddleware - мидлвар для обработки HTTP запросов метрик.
+// Kernel подключает его глобально, повторное подключение не приводит к двойному учету
 func MetricsMiddleware(a *app.App) gin.HandlerFunc {
 	return func(c *gin.Context) {
+		if c.GetBool(ctxKeyMetricsCollected) {
+			return
+		}
+
+		c.Set(ctxKeyMetricsCollected, true)
+
 		start := time.Now()
-		c.Next()
 
 		metricsCollector, err := di.GetMetricsCollector(a.Container)
 		if err != nil || metricsCollector == nil {
+			c.Next()
+
 			return
 		}
 
+		path := gin2.GetRoutePath(c)
+
+		method := c.Request.Method
+		// размер тела до c.Next: DecompressionMiddleware сбрасывает ContentLength распакованного тела,
+		// а в метрике нужен размер, пришедший по сети. -1 у chunked запросов, размер неизвестен и не учитывается
+		requestSize := c.Request.ContentLength
+
+		// паника перехватывается recovery выше по цепочке, здесь только учитываем ее и пробрасываем дальше
+		defer func() {
+			if p := recover(); p != nil {
+				metricsCollector.IncPanics(method, path)
+				metricsCollector.GetMetrics(http.StatusInternalServerError, method, path, time.Since(start).Seconds())
+
+				panic(p)
+			}
+		}()
+
+		c.Next()
+
 		duration := time.Since(start).Seconds()
 		statusCode := c.Writer.Status()
 
@@ -35,11 +74,59 @@ func MetricsMiddleware(a *app.App) gin.HandlerFunc {
 			}
 		}
 
-		path := c.FullPath()
-		if path == "" {
-			path = "__unknown__"
+		// хендлер не уложился в HANDLER_TIMEOUT, клиент уже получил 503 от timeout middleware
+		if errors.Is(c.Request.Context().Err(), context.DeadlineExceeded) {
+			metricsCollector.IncTimeouts(method, path)
+			statusCode = http.StatusServiceUnavailable
+		}
+
+		metricsCollector.GetMetricsWithExemplar(statusCode, method, path, duration, exemplarLabels(c, metricsCollector))
+		// Size() возвращает -1, если тело ответа не писалось
+		responseSize := int64(c.Writer.Size())
+		if responseSize < 0 {
+			responseSize = 0
+		}
+
+		metricsCollector.ObserveSizes(statusCode, method, path, requestSize, responseSize)
+	}
+}
+
+// exemplarLabels собирает exemplar для запроса: request id и trace id, если он есть
+func exemplarLabels(c *gin.Context, metricsCollector *metrics.Collector) prometheus.Labels {
+	if !metricsCollector.IsExemplarsEnabled() {
+		return nil
+	}
+
+	labels := prometheus.Labels{}
+
+	if spanContext := trace.SpanContextFromContext(c.Request.Context()); spanContext.IsValid() {
+		labels[metrics.ExemplarLabelTraceId] = spanContext.TraceID().String()
+	} else if span := sentry.TransactionFromContext(c.Request.Context()); span != nil && span.TraceID != (sentry.TraceID{}) {
+		labels[metrics.ExemplarLabelTraceId] = span.TraceID.String()
+	}
+
+	requestId := c.GetHeader(constants.RequestIdHeaderName)
+
+	if httpInfo := gin2.GetHttpInfoFromContext(c.Request.Context()); httpInfo != nil && httpInfo.RequestId != "" {
+		requestId = httpInfo.RequestId
+	}
+
+	// request id приходит от клиента: невалидный UTF-8 не пишем, длинный обрезаем до лимита exemplar
+	if requestId != "" && utf8.ValidString(requestId) {
+		budget := prometheus.ExemplarMaxRunes - utf8.RuneCountInString(metrics.ExemplarLabelRequestId)
+
+		for name, value := range labels {
+			budget -= utf8.RuneCountInString(name) + utf8.RuneCountInString(value)
 		}
 
-		metricsCollector.GetMetrics(statusCode, c.Request.Method, path, duration)
+		if runes := []rune(requestId); len(runes) > budget {
+			requestId = string(runes[:max(budget, 0)])
+		}
+
+		if requestId != "" {
+			labels[metrics.ExemplarLabelRequestId] = requestId
+		}
 	}
+
+	return labels
 }
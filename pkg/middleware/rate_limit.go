@@ -0,0 +1,66 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/exgamer/gosdk-core/pkg/logger"
+	"github.com/exgamer/gosdk-http-core/pkg/ratelimit"
+	"github.com/exgamer/gosdk-http-core/pkg/response"
+	"github.com/gin-gonic/gin"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ErrRateLimited запрос отклонен ограничителем
+var ErrRateLimited = errors.New("too many requests")
+
+// RateLimitMiddleware ограничивает запросы по правилу limiter, отдает заголовки RateLimit-Limit, RateLimit-Remaining,
+// RateLimit-Reset, RateLimit-Policy, а при превышении - 429 с Retry-After.
+// Если хранилище недоступно, запрос пропускается: лимиты не должны ронять сервис.
+// Ошибка - если limiter настроен неверно
+func RateLimitMiddleware(limiter *ratelimit.Limiter) (gin.HandlerFunc, error) {
+	if err := limiter.Validate(); err != nil {
+		return nil, err
+	}
+
+	policy := limiter.Rule.Policy()
+
+	return func(c *gin.Context) {
+		result, ok, err := limiter.Take(c)
+
+		if err != nil {
+			logger.Error(c.Request.Context(), "rate limit store error: "+err.Error())
+		}
+
+		if !ok {
+			c.Next()
+
+			return
+		}
+
+		header := c.Writer.Header()
+		header.Set("RateLimit-Limit", strconv.Itoa(result.Limit))
+		header.Set("RateLimit-Remaining", strconv.Itoa(max(result.Remaining, 0)))
+		header.Set("RateLimit-Reset", strconv.Itoa(durationSeconds(result.ResetAfter)))
+		header.Set("RateLimit-Policy", policy)
+
+		if !result.Allowed {
+			retryAfter := durationSeconds(result.RetryAfter)
+			header.Set("Retry-After", strconv.Itoa(retryAfter))
+			response.AbortWithError(c, http.StatusTooManyRequests, "", ErrRateLimited, map[string]any{
+				"limiter":     limiter.Name,
+				"retry_after": retryAfter,
+			})
+
+			return
+		}
+
+		c.Next()
+	}, nil
+}
+
+// durationSeconds целые секунды с округлением вверх
+func durationSeconds(duration time.Duration) int {
+	return int(math.Ceil(duration.Seconds()))
+}
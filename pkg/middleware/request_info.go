@@ -2,22 +2,69 @@ package middleware
 
 import (
 	"context"
+	"errors"
 	"github.com/exgamer/gosdk-core/pkg/app"
 	"github.com/exgamer/gosdk-http-core/pkg/constants"
 	gin2 "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"github.com/exgamer/gosdk-http-core/pkg/response"
 	"github.com/gin-gonic/gin"
 )
 
-// RequestInfoMiddleware Middleware заполняющий данные запроса
-func RequestInfoMiddleware(a *app.App) gin.HandlerFunc {
+// RequestInfoMiddleware Middleware заполняющий данные запроса.
+// extractors дополняют HttpInfo доменными заголовками сервиса.
+// Если заголовки идентификации некорректны или экстрактор вернул ошибку, запрос отклоняется с 400
+func RequestInfoMiddleware(a *app.App, extractors ...gin2.HttpInfoExtractor) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		httpInfo := gin2.GetInstanceHttpInfo(c)
+		httpInfo, err := gin2.ParseHttpInfo(c)
+
+		for _, extractor := range extractors {
+			err = joinHeaderErrors(err, extractor(c, httpInfo))
+		}
 
 		ctx := c.Request.Context()
 		ctx = context.WithValue(ctx, constants.HttpInfoKey, httpInfo)
 
 		c.Request = c.Request.WithContext(ctx)
 
+		if err != nil {
+			var details map[string]any
+
+			var invalidHeaders *gin2.InvalidHeadersError
+			if errors.As(err, &invalidHeaders) {
+				details = invalidHeaders.Details
+			}
+
+			response.BadRequest(c, err, details)
+			// RequestInfoMiddleware подключается первым, до FormattedResponseMiddleware, поэтому ответ отдаем сами
+			response.Formatted(c)
+
+			return
+		}
+
 		c.Next()
 	}
 }
+
+// joinHeaderErrors объединяет ошибки разбора заголовков, details *InvalidHeadersError сливаются в одну
+func joinHeaderErrors(err error, next error) error {
+	if next == nil {
+		return err
+	}
+
+	if err == nil {
+		return next
+	}
+
+	invalidHeaders, isInvalidHeaders := err.(*gin2.InvalidHeadersError)
+
+	var nextInvalidHeaders *gin2.InvalidHeadersError
+	if isInvalidHeaders && errors.As(next, &nextInvalidHeaders) {
+		for name, detail := range nextInvalidHeaders.Details {
+			invalidHeaders.Details[name] = detail
+		}
+
+		return err
+	}
+
+	return errors.Join(err, next)
+}
@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"errors"
+	"fmt"
+	"github.com/exgamer/gosdk-http-core/pkg/exception"
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// TracingMiddleware Middleware, который продолжает трейс из заголовков запроса (traceparent/tracestate, b3)
+// и стартует серверный спан с именем по шаблону роута. Ошибка из exception попадает в спан
+func TracingMiddleware(tracer trace.Tracer, propagator propagation.TextMapPropagator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := propagator.Extract(c.Request.Context(), propagation.HeaderCarrier(c.Request.Header))
+
+		route := c.FullPath()
+		spanName := c.Request.Method
+
+		if route != "" {
+			spanName += " " + route
+		}
+
+		ctx, span := tracer.Start(ctx, spanName,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(c.Request.Method),
+				semconv.URLPath(c.Request.URL.Path),
+				semconv.ServerAddress(c.Request.Host),
+				semconv.ClientAddress(c.ClientIP()),
+				semconv.UserAgentOriginal(c.Request.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		if route != "" {
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+
+		status := c.Writer.Status()
+		statusMessage := c.Errors.String()
+
+		if exObj, exists := c.Get("exception"); exists {
+			err, ok := exObj.(error)
+			if !ok {
+				err = fmt.Errorf("exception in context is not error: %T", exObj)
+			}
+
+			errorType := fmt.Sprintf("%T", err)
+
+			var httpEx *exception.HttpException
+			if errors.As(err, &httpEx) {
+				status = httpEx.Code
+				errorType = httpEx.GetErrorType()
+			}
+
+			span.RecordError(err)
+			span.SetAttributes(semconv.ErrorTypeKey.String(errorType))
+			statusMessage = err.Error()
+		}
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(status))
+
+		// для серверного спана ошибкой считаются только 5xx
+		if status >= 500 {
+			span.SetStatus(codes.Error, statusMessage)
+		}
+	}
+}
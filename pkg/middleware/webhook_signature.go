@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"github.com/exgamer/gosdk-http-core/pkg/response"
+	"github.com/exgamer/gosdk-http-core/pkg/webhook"
+	"github.com/gin-gonic/gin"
+	"io"
+	"net/http"
+)
+
+// WebhookSignatureMiddleware проверяет HMAC подпись webhook запроса.
+// Тело читается целиком и подменяется копией, поэтому validators.ValidateRequestBody читает его как обычно.
+// Запрос с невалидной подписью, просроченный или повторный отклоняется с 401
+func WebhookSignatureMiddleware(verifier *webhook.Verifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var body []byte
+
+		if c.Request.Body != nil {
+			var err error
+			body, err = io.ReadAll(http.MaxBytesReader(c.Writer, c.Request.Body, verifier.GetMaxBodyBytes()))
+
+			if err != nil {
+				var maxBytesErr *http.MaxBytesError
+				if errors.As(err, &maxBytesErr) {
+					response.AbortWithError(c, http.StatusRequestEntityTooLarge, "", ErrBodyTooLarge, map[string]any{
+						"limit": maxBytesErr.Limit,
+					})
+
+					return
+				}
+
+				response.AbortWithError(c, http.StatusBadRequest, "", err, nil)
+
+				return
+			}
+
+			// копия остается под лимитом BodyLimitMiddleware: лимит роута после подписи действует и на нее
+			c.Request.Body = wrapWithBodyLimit(c, io.NopCloser(bytes.NewReader(body)), int64(len(body)))
+			c.Set(gin.BodyBytesKey, body)
+		}
+
+		if err := verifier.Verify(c.Request.Context(), c.Request, body); err != nil {
+			if isWebhookRequestError(err) {
+				response.AbortWithError(c, http.StatusUnauthorized, "", err, nil)
+
+				return
+			}
+
+			// ошибка nonce cache
+			response.AbortWithError(c, http.StatusInternalServerError, "", err, nil)
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func isWebhookRequestError(err error) bool {
+	for _, target := range []error{
+		webhook.ErrSignatureMissing,
+		webhook.ErrSignatureInvalid,
+		webhook.ErrTimestampInvalid,
+		webhook.ErrTimestampSkewed,
+		webhook.ErrReplayed,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+
+	return false
+}
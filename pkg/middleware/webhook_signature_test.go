@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/exgamer/gosdk-http-core/pkg/webhook"
+	"github.com/gin-gonic/gin"
+)
+
+func newWebhookRequest(body string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/upload", strings.NewReader(body))
+	request.ContentLength = -1
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	request.Header.Set(webhook.DefaultTimestampHeader, timestamp)
+	request.Header.Set(webhook.DefaultSignatureHeader, webhook.Sign([]byte("secret"), http.MethodPost, "/upload", timestamp, "", []byte(body)))
+
+	return request
+}
+
+func TestWebhookSignatureMiddlewareKeepsBodyLimit(t *testing.T) {
+	tests := []struct {
+		name       string
+		routeLimit int64
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "within route limit", routeLimit: 100, wantStatus: http.StatusOK, wantBody: "50"},
+		{name: "route limit after signature", routeLimit: 10, wantStatus: http.StatusRequestEntityTooLarge, wantBody: "10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := gin.New()
+			router.Use(BodyLimitMiddleware(1000))
+			router.POST("/upload", WebhookSignatureMiddleware(webhook.NewVerifier("secret")), BodyLimitMiddleware(tt.routeLimit), readBodyHandler)
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, newWebhookRequest(strings.Repeat("a", 50)))
+
+			if recorder.Code != tt.wantStatus || recorder.Body.String() != tt.wantBody {
+				t.Fatalf("response = %d %q, want %d %q", recorder.Code, recorder.Body.String(), tt.wantStatus, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestWebhookSignatureMiddlewareRejectsInvalidSignature(t *testing.T) {
+	router := gin.New()
+	router.POST("/upload", WebhookSignatureMiddleware(webhook.NewVerifier("other")), readBodyHandler)
+
+	recorder := httptest.NewRecorder()
+	router.ServeHTTP(recorder, newWebhookRequest("{}"))
+
+	if recorder.Code != http.StatusUnauthorized || !strings.Contains(recorder.Body.String(), `"error":"unauthorized"`) {
+		t.Fatalf("response = %d %s, want 401 envelope", recorder.Code, recorder.Body.String())
+	}
+}
@@ -0,0 +1,125 @@
+package ratelimit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/exgamer/gosdk-http-core/pkg/apikey"
+	"github.com/exgamer/gosdk-http-core/pkg/constants"
+	gin2 "github.com/exgamer/gosdk-http-core/pkg/gin"
+	"github.com/gin-gonic/gin"
+	"strconv"
+	"strings"
+)
+
+// KeyFunc ключ, по которому считается лимит. Пустой ключ - запрос не ограничивается
+type KeyFunc func(c *gin.Context) string
+
+// NewLimiter ограничитель с хранилищем в памяти инстанса
+func NewLimiter(name string, rule Rule, key KeyFunc) *Limiter {
+	return &Limiter{Name: name, Rule: rule, Key: key, Store: NewMemoryStore()}
+}
+
+// Limiter ограничитель запросов: правило, ключ и хранилище
+type Limiter struct {
+	// Name префикс ключей в хранилище, разделяет лимиты с одинаковыми ключами
+	Name  string
+	Rule  Rule
+	Key   KeyFunc
+	Store Store
+}
+
+// Validate проверяет настройки ограничителя
+func (l *Limiter) Validate() error {
+	if l.Rule.Limit <= 0 || l.Rule.Period <= 0 {
+		return errors.New("ratelimit: rule limit and period must be positive")
+	}
+
+	if l.Rule.Burst < 0 {
+		return errors.New("ratelimit: rule burst must be >= 0")
+	}
+
+	if l.Key == nil || l.Store == nil {
+		return errors.New("ratelimit: limiter key and store are required")
+	}
+
+	return nil
+}
+
+// Take списывает запрос. ok=false - ключ пустой и запрос не ограничивается
+func (l *Limiter) Take(c *gin.Context) (result Result, ok bool, err error) {
+	key := l.Key(c)
+
+	if key == "" {
+		return Result{}, false, nil
+	}
+
+	result, err = l.Store.Take(c.Request.Context(), l.Name+":"+key, l.Rule)
+
+	return result, err == nil, err
+}
+
+// KeyByIp ключ по IP клиента (с учетом доверенных прокси gin)
+func KeyByIp(c *gin.Context) string {
+	return "ip:" + c.ClientIP()
+}
+
+// KeyByUserId ключ по заголовку User-Id, запросы без пользователя не ограничиваются
+func KeyByUserId(c *gin.Context) string {
+	if httpInfo := gin2.GetHttpInfoFromContext(c.Request.Context()); httpInfo != nil && httpInfo.UserId > 0 {
+		return "user:" + strconv.Itoa(httpInfo.UserId)
+	}
+
+	if userId := strings.TrimSpace(c.GetHeader(constants.UserHeaderName)); userId != "" {
+		return "user:" + userId
+	}
+
+	return ""
+}
+
+// KeyByApiKey ключ по клиенту API ключа: id клиента после ApiKeyMiddleware, иначе хеш ключа из заголовка header
+// (API_KEY_HEADER, пустой - X-Api-Key). Запросы без ключа не ограничиваются
+func KeyByApiKey(header string) KeyFunc {
+	if header == "" {
+		header = apikey.DefaultHeader
+	}
+
+	return func(c *gin.Context) string {
+		if httpInfo := gin2.GetHttpInfoFromContext(c.Request.Context()); httpInfo != nil && httpInfo.ApiClientId != "" {
+			return "api_client:" + httpInfo.ApiClientId
+		}
+
+		if key := c.GetHeader(header); key != "" {
+			hash := sha256.Sum256([]byte(key))
+
+			return "api_key:" + hex.EncodeToString(hash[:])
+		}
+
+		return ""
+	}
+}
+
+// KeyByRoute ключ по шаблону роута - общий лимит роута для всех клиентов
+func KeyByRoute(c *gin.Context) string {
+	return "route:" + c.Request.Method + " " + gin2.GetRoutePath(c)
+}
+
+// KeyBy составной ключ, например лимит пользователя на конкретный роут: KeyBy(KeyByUserId, KeyByRoute).
+// Если любая часть пустая - запрос не ограничивается
+func KeyBy(keys ...KeyFunc) KeyFunc {
+	return func(c *gin.Context) string {
+		parts := make([]string, 0, len(keys))
+
+		for _, key := range keys {
+			part := key(c)
+
+			if part == "" {
+				return ""
+			}
+
+			parts = append(parts, part)
+		}
+
+		return strings.Join(parts, "|")
+	}
+}
@@ -0,0 +1,64 @@
+package ratelimit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+func newTestContext(header, value string) *gin.Context {
+	c, _ := gin.CreateTestContext(httptest.NewRecorder())
+	c.Request = httptest.NewRequest(http.MethodGet, "/orders", nil)
+
+	if header != "" {
+		c.Request.Header.Set(header, value)
+	}
+
+	return c
+}
+
+func TestKeyByApiKey(t *testing.T) {
+	tests := []struct {
+		name    string
+		header  string
+		request *gin.Context
+		wantKey bool
+	}{
+		{name: "default header", request: newTestContext("X-Api-Key", "key"), wantKey: true},
+		{name: "configured header", header: "X-Partner-Key", request: newTestContext("X-Partner-Key", "key"), wantKey: true},
+		{name: "default header is ignored when configured", header: "X-Partner-Key", request: newTestContext("X-Api-Key", "key")},
+		{name: "without key", request: newTestContext("", "")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if key := KeyByApiKey(tt.header)(tt.request); (key != "") != tt.wantKey {
+				t.Fatalf("KeyByApiKey(%q) = %q, want key %v", tt.header, key, tt.wantKey)
+			}
+		})
+	}
+}
+
+func TestLimiterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		limiter *Limiter
+		wantErr bool
+	}{
+		{name: "valid", limiter: NewLimiter("ip", Rule{Limit: 1, Period: time.Second}, KeyByIp)},
+		{name: "zero limit", limiter: NewLimiter("ip", Rule{Period: time.Second}, KeyByIp), wantErr: true},
+		{name: "negative burst", limiter: NewLimiter("ip", Rule{Limit: 1, Period: time.Second, Burst: -1}, KeyByIp), wantErr: true},
+		{name: "without key", limiter: NewLimiter("ip", Rule{Limit: 1, Period: time.Second}, nil), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.limiter.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
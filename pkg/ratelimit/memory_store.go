@@ -0,0 +1,154 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// memoryCleanupInterval как часто удаляются неактивные ключи
+const memoryCleanupInterval = time.Minute
+
+// NewMemoryStore хранилище лимитов в памяти инстанса. Лимит считается отдельно на каждом инстансе
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{entries: make(map[string]*memoryEntry), now: time.Now}
+}
+
+// MemoryStore хранилище лимитов в памяти инстанса
+type MemoryStore struct {
+	mu        sync.Mutex
+	entries   map[string]*memoryEntry
+	cleanedAt time.Time
+	now       func() time.Time
+}
+
+type memoryEntry struct {
+	// TokenBucket: оставшиеся токены на момент updatedAt
+	tokens float64
+	// SlidingWindow: начало текущего окна, запросы в текущем и предыдущем окне
+	windowStart   time.Time
+	current       int
+	previous      int
+	updatedAt     time.Time
+	expiresAfter  time.Duration
+	isInitialized bool
+}
+
+func (s *MemoryStore) Take(_ context.Context, key string, rule Rule) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.cleanup(now)
+
+	entry, ok := s.entries[key]
+
+	if !ok {
+		entry = &memoryEntry{}
+		s.entries[key] = entry
+	}
+
+	// неактивный ключ хранится, пока лимит не восстановится полностью
+	entry.expiresAfter = 2 * rule.Period
+
+	if rule.Algorithm == SlidingWindow {
+		return entry.takeSlidingWindow(now, rule), nil
+	}
+
+	return entry.takeTokenBucket(now, rule), nil
+}
+
+func (s *MemoryStore) cleanup(now time.Time) {
+	if now.Sub(s.cleanedAt) < memoryCleanupInterval {
+		return
+	}
+
+	for key, entry := range s.entries {
+		if now.Sub(entry.updatedAt) > entry.expiresAfter {
+			delete(s.entries, key)
+		}
+	}
+
+	s.cleanedAt = now
+}
+
+func (e *memoryEntry) takeTokenBucket(now time.Time, rule Rule) Result {
+	capacity := float64(rule.GetCapacity())
+	rate := float64(rule.Limit) / rule.Period.Seconds()
+
+	if !e.isInitialized {
+		e.tokens = capacity
+		e.isInitialized = true
+	} else {
+		e.tokens = math.Min(capacity, e.tokens+now.Sub(e.updatedAt).Seconds()*rate)
+	}
+
+	e.updatedAt = now
+	result := Result{Limit: rule.GetCapacity()}
+
+	if e.tokens >= 1 {
+		e.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = secondsToDuration((1 - e.tokens) / rate)
+	}
+
+	result.Remaining = int(e.tokens)
+	result.ResetAfter = secondsToDuration((capacity - e.tokens) / rate)
+
+	return result
+}
+
+func (e *memoryEntry) takeSlidingWindow(now time.Time, rule Rule) Result {
+	windowStart := now.Truncate(rule.Period)
+
+	if !windowStart.Equal(e.windowStart) {
+		if windowStart.Sub(e.windowStart) == rule.Period {
+			e.previous = e.current
+		} else {
+			e.previous = 0
+		}
+
+		e.current = 0
+		e.windowStart = windowStart
+	}
+
+	e.updatedAt = now
+	elapsed := now.Sub(windowStart)
+	// доля предыдущего окна, которая еще попадает в скользящее окно
+	weight := 1 - elapsed.Seconds()/rule.Period.Seconds()
+	estimated := float64(e.previous)*weight + float64(e.current)
+	result := Result{Limit: rule.Limit, ResetAfter: rule.Period - elapsed}
+
+	if estimated+1 <= float64(rule.Limit) {
+		e.current++
+		result.Allowed = true
+		result.Remaining = int(float64(rule.Limit) - estimated - 1)
+	}
+
+	// запросы текущего окна перестают учитываться только в конце следующего
+	if e.current > 0 {
+		result.ResetAfter += rule.Period
+	}
+
+	if result.Allowed {
+		return result
+	}
+
+	// ждем, пока вклад предыдущего окна уменьшится настолько, что запрос поместится,
+	// а если не хватает и текущего окна - до следующего окна
+	free := float64(rule.Limit - e.current - 1)
+
+	if free < 0 || e.previous == 0 {
+		result.RetryAfter = rule.Period - elapsed
+	} else {
+		result.RetryAfter = secondsToDuration(rule.Period.Seconds()*(1-free/float64(e.previous))) - elapsed
+	}
+
+	return result
+}
+
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
@@ -0,0 +1,192 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// testClock управляемое время для MemoryStore
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time {
+	return c.now
+}
+
+func (c *testClock) Advance(d time.Duration) {
+	c.now = c.now.Add(d)
+}
+
+func newTestStore() (*MemoryStore, *testClock) {
+	clock := &testClock{now: time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)}
+	store := NewMemoryStore()
+	store.now = clock.Now
+
+	return store, clock
+}
+
+func take(t *testing.T, store *MemoryStore, key string, rule Rule) Result {
+	t.Helper()
+
+	result, err := store.Take(context.Background(), key, rule)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return result
+}
+
+func TestTokenBucket(t *testing.T) {
+	store, clock := newTestStore()
+	rule := Rule{Algorithm: TokenBucket, Limit: 10, Period: time.Second, Burst: 5}
+
+	for i := 0; i < 5; i++ {
+		result := take(t, store, "k", rule)
+
+		if !result.Allowed || result.Limit != 5 || result.Remaining != 4-i {
+			t.Fatalf("take %d = %+v, want allowed with remaining %d", i+1, result, 4-i)
+		}
+	}
+
+	result := take(t, store, "k", rule)
+
+	// корзина пуста, токен пополняется за Period/Limit
+	if result.Allowed || result.RetryAfter != 100*time.Millisecond || result.ResetAfter != 500*time.Millisecond {
+		t.Fatalf("take over burst = %+v, want denied with retry after 100ms and reset after 500ms", result)
+	}
+
+	clock.Advance(100 * time.Millisecond)
+
+	if result := take(t, store, "k", rule); !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("take after refill = %+v, want allowed with remaining 0", result)
+	}
+
+	if result := take(t, store, "k", rule); result.Allowed {
+		t.Fatalf("take after refill = %+v, want denied", result)
+	}
+
+	// корзина не наполняется больше Burst
+	clock.Advance(time.Hour)
+
+	if result := take(t, store, "k", rule); !result.Allowed || result.Remaining != 4 {
+		t.Fatalf("take after idle = %+v, want allowed with remaining 4", result)
+	}
+}
+
+func TestTokenBucketWithoutBurst(t *testing.T) {
+	store, _ := newTestStore()
+	rule := Rule{Algorithm: TokenBucket, Limit: 3, Period: time.Minute}
+
+	for i := 0; i < 3; i++ {
+		if result := take(t, store, "k", rule); !result.Allowed {
+			t.Fatalf("take %d = %+v, want allowed", i+1, result)
+		}
+	}
+
+	if result := take(t, store, "k", rule); result.Allowed || result.RetryAfter != 20*time.Second {
+		t.Fatalf("take over limit = %+v, want denied with retry after 20s", result)
+	}
+}
+
+func TestSlidingWindow(t *testing.T) {
+	store, clock := newTestStore()
+	rule := Rule{Algorithm: SlidingWindow, Limit: 10, Period: time.Minute}
+
+	clock.Advance(20 * time.Second)
+
+	for i := 0; i < 10; i++ {
+		if result := take(t, store, "k", rule); !result.Allowed || result.Remaining != 9-i {
+			t.Fatalf("take %d = %+v, want allowed with remaining %d", i+1, result, 9-i)
+		}
+	}
+
+	result := take(t, store, "k", rule)
+
+	// текущее окно заполнено - ждем следующего
+	if result.Allowed || result.RetryAfter != 40*time.Second {
+		t.Fatalf("take over limit = %+v, want denied with retry after 40s", result)
+	}
+
+	// середина следующего окна: предыдущее учитывается с весом 0.5, то есть как 5 запросов
+	clock.Advance(70 * time.Second)
+
+	for i := 0; i < 4; i++ {
+		if result := take(t, store, "k", rule); !result.Allowed {
+			t.Fatalf("take %d in next window = %+v, want allowed", i+1, result)
+		}
+	}
+
+	result = take(t, store, "k", rule)
+
+	// 4 запроса текущего окна + 1 новый помещаются, когда вклад предыдущего окна упадет до 5 запросов
+	if !result.Allowed || result.Remaining != 0 {
+		t.Fatalf("take 5 in next window = %+v, want allowed with remaining 0", result)
+	}
+
+	result = take(t, store, "k", rule)
+
+	// вклад предыдущего окна должен упасть до 4 запросов: вес 0.4 через 36s от начала окна
+	if result.Allowed || result.RetryAfter != 6*time.Second {
+		t.Fatalf("take 6 in next window = %+v, want denied with retry after 6s", result)
+	}
+
+	clock.Advance(6 * time.Second)
+
+	if result := take(t, store, "k", rule); !result.Allowed {
+		t.Fatalf("take after retry after = %+v, want allowed", result)
+	}
+}
+
+func TestSlidingWindowSkipsIdleWindows(t *testing.T) {
+	store, clock := newTestStore()
+	rule := Rule{Algorithm: SlidingWindow, Limit: 2, Period: time.Minute}
+
+	take(t, store, "k", rule)
+	take(t, store, "k", rule)
+
+	// предыдущее окно пустое, запросы двух окон назад не учитываются
+	clock.Advance(2 * time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if result := take(t, store, "k", rule); !result.Allowed {
+			t.Fatalf("take %d after idle = %+v, want allowed", i+1, result)
+		}
+	}
+}
+
+func TestMemoryStoreKeysAreIndependent(t *testing.T) {
+	store, _ := newTestStore()
+	rule := Rule{Algorithm: TokenBucket, Limit: 1, Period: time.Minute}
+
+	if result := take(t, store, "a", rule); !result.Allowed {
+		t.Fatalf("take a = %+v, want allowed", result)
+	}
+
+	if result := take(t, store, "a", rule); result.Allowed {
+		t.Fatalf("second take a = %+v, want denied", result)
+	}
+
+	if result := take(t, store, "b", rule); !result.Allowed {
+		t.Fatalf("take b = %+v, want allowed", result)
+	}
+}
+
+func TestRulePolicy(t *testing.T) {
+	tests := []struct {
+		rule Rule
+		want string
+	}{
+		{rule: Rule{Algorithm: TokenBucket, Limit: 100, Period: time.Minute}, want: "100;w=60"},
+		{rule: Rule{Algorithm: TokenBucket, Limit: 100, Period: time.Minute, Burst: 20}, want: "100;w=60;burst=20"},
+		{rule: Rule{Algorithm: SlidingWindow, Limit: 100, Period: time.Minute, Burst: 20}, want: "100;w=60"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.rule.Policy(); got != tt.want {
+			t.Errorf("Policy() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"strconv"
+	"time"
+)
+
+// Algorithm алгоритм ограничения
+type Algorithm int
+
+const (
+	// TokenBucket корзина на Burst запросов, пополняется со скоростью Limit за Period. Допускает всплески
+	TokenBucket Algorithm = iota
+	// SlidingWindow не больше Limit запросов за любые Period (скользящее окно по двум соседним окнам)
+	SlidingWindow
+)
+
+// Rule правило ограничения
+type Rule struct {
+	Algorithm Algorithm
+	// Limit сколько запросов разрешено за Period
+	Limit  int
+	Period time.Duration
+	// Burst размер корзины TokenBucket, 0 - Limit
+	Burst int
+}
+
+// GetCapacity максимальное число запросов, которое можно выполнить сразу
+func (r Rule) GetCapacity() int {
+	if r.Algorithm == TokenBucket && r.Burst > 0 {
+		return r.Burst
+	}
+
+	return r.Limit
+}
+
+// Policy значение заголовка RateLimit-Policy, например "100;w=60"
+func (r Rule) Policy() string {
+	policy := strconv.Itoa(r.Limit) + ";w=" + strconv.Itoa(int(r.Period.Seconds()))
+
+	if r.Algorithm == TokenBucket && r.Burst > 0 {
+		policy += ";burst=" + strconv.Itoa(r.Burst)
+	}
+
+	return policy
+}
+
+// Result результат списания запроса
+type Result struct {
+	Allowed bool
+	// Limit максимальное число запросов (RateLimit-Limit)
+	Limit int
+	// Remaining сколько запросов еще можно выполнить (RateLimit-Remaining)
+	Remaining int
+	// ResetAfter через сколько лимит полностью восстановится (RateLimit-Reset)
+	ResetAfter time.Duration
+	// RetryAfter через сколько можно повторить отклоненный запрос (Retry-After)
+	RetryAfter time.Duration
+}
+
+// Store хранилище состояния лимитов. Take должен быть атомарным: для redis и подобных
+// хранилищ алгоритм реализуется скриптом на стороне хранилища
+type Store interface {
+	// Take списывает один запрос по ключу key
+	Take(ctx context.Context, key string, rule Rule) (Result, error)
+}
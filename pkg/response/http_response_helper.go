@@ -88,6 +88,18 @@ func ErrorResponseWithStatus(c *gin.Context, statusCode int, err error, context
 	ErrorResponse(c, exception.NewHttpException(statusCode, err, context))
 }
 
+// AbortWithError прерывает запрос ошибкой status и сразу отдает ответ в стандартном конверте.
+// Для middleware, которые отклоняют запрос и могут стоять раньше FormattedResponseMiddleware (или вовсе без него).
+// errorType пустой - тип по статусу. В sentry отправляются только 500, отказы клиенту (401, 429, 503 и т.п.) - нет
+func AbortWithError(c *gin.Context, status int, errorType string, err error, details map[string]any) {
+	httpErr := exception.NewHttpException(status, err, details)
+	httpErr.TrackInSentry = status == http.StatusInternalServerError
+	httpErr.ErrorType = errorType
+
+	ErrorResponse(c, httpErr)
+	Formatted(c)
+}
+
 // ---------- базовые (error) ----------
 
 func BadRequest(c *gin.Context, err error, ctx map[string]any) {
@@ -114,6 +126,10 @@ func UnprocessableEntity(c *gin.Context, err error, ctx map[string]any) {
 	ErrorResponseWithStatus(c, http.StatusUnprocessableEntity, err, ctx)
 }
 
+func PayloadTooLarge(c *gin.Context, err error, ctx map[string]any) {
+	ErrorResponseWithStatus(c, http.StatusRequestEntityTooLarge, err, ctx)
+}
+
 func TooManyRequests(c *gin.Context, err error, ctx map[string]any) {
 	ErrorResponseWithStatus(c, http.StatusTooManyRequests, err, ctx)
 }
@@ -142,6 +158,11 @@ func FormattedSuccessResponse(c *gin.Context, data any) {
 }
 
 func Formatted(c *gin.Context) {
+	// тело ответа уже отправлено (например, middleware отклонило запрос и отдало ответ само)
+	if c.Writer.Size() > 0 {
+		return
+	}
+
 	// ---- ERROR PATH ----
 	if exObj, exists := c.Get(ctxKeyException); exists {
 		err, ok := exObj.(error)
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"context"
+	baseConfig "github.com/exgamer/gosdk-core/pkg/config"
+	"go.opentelemetry.io/contrib/propagators/b3"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.37.0"
+)
+
+// TracerName имя трейсера HTTP слоя
+const TracerName = "github.com/exgamer/gosdk-http-core"
+
+// NewOtlpExporter создает экспортер спанов по OTLP/HTTP.
+// Если endpoint пустой, используются стандартные переменные OTEL_EXPORTER_OTLP_* (по умолчанию localhost:4318)
+func NewOtlpExporter(ctx context.Context, endpoint string) (sdktrace.SpanExporter, error) {
+	opts := make([]otlptracehttp.Option, 0, 1)
+
+	if endpoint != "" {
+		opts = append(opts, otlptracehttp.WithEndpointURL(endpoint))
+	}
+
+	return otlptracehttp.New(ctx, opts...)
+}
+
+// NewTracerProvider создает TracerProvider с ресурсом сервиса и сэмплированием по доле трейсов.
+// Решение о сэмплировании родительского спана (из traceparent/b3) уважается
+func NewTracerProvider(baseConfig *baseConfig.BaseConfig, exporter sdktrace.SpanExporter, sampleRatio float64) (*sdktrace.TracerProvider, error) {
+	attrs := make([]attribute.KeyValue, 0, 3)
+
+	if baseConfig != nil {
+		attrs = append(attrs,
+			semconv.ServiceName(baseConfig.Name),
+			semconv.ServiceVersion(baseConfig.Version),
+			semconv.DeploymentEnvironmentName(baseConfig.AppEnv),
+		)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(semconv.SchemaURL, attrs...))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+	), nil
+}
+
+// NewPropagator пропагатор W3C traceparent/tracestate + baggage, дополнительно понимает B3 (single и multi header)
+func NewPropagator() propagation.TextMapPropagator {
+	return propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+		b3.New(b3.WithInjectEncoding(b3.B3MultipleHeader|b3.B3SingleHeader)),
+	)
+}
@@ -83,6 +83,15 @@ func ValidateRequestBody(c *gin.Context, request validation.IRequest) bool {
 	}
 
 	if err := c.ShouldBind(request); err != nil {
+		// тело больше лимита BodyLimitMiddleware
+		var maxBytesError *http.MaxBytesError
+
+		if errors.As(err, &maxBytesError) {
+			response.ErrorResponseUntrackableSentry(c, http.StatusRequestEntityTooLarge, errors.New("request body is too large"), map[string]any{"limit": maxBytesError.Limit})
+
+			return false
+		}
+
 		var ve validator.ValidationErrors
 
 		if errors.As(err, &ve) {
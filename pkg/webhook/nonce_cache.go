@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// NonceCache хранилище использованных nonce для защиты от повтора запросов.
+// Для нескольких инстансов сервиса реализуется поверх общего хранилища (например, redis SET NX EX)
+type NonceCache interface {
+	// Add запоминает nonce на ttl. false - nonce уже использовался
+	Add(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// NewMemoryNonceCache nonce cache в памяти инстанса
+func NewMemoryNonceCache() *MemoryNonceCache {
+	return &MemoryNonceCache{nonces: make(map[string]time.Time)}
+}
+
+// MemoryNonceCache nonce cache в памяти инстанса. Просроченные nonce удаляются при добавлении новых
+type MemoryNonceCache struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	cleanedAt time.Time
+}
+
+func (m *MemoryNonceCache) Add(_ context.Context, nonce string, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+
+	if now.Sub(m.cleanedAt) > ttl {
+		for n, expiresAt := range m.nonces {
+			if now.After(expiresAt) {
+				delete(m.nonces, n)
+			}
+		}
+
+		m.cleanedAt = now
+	}
+
+	if expiresAt, ok := m.nonces[nonce]; ok && now.Before(expiresAt) {
+		return false, nil
+	}
+
+	m.nonces[nonce] = now.Add(ttl)
+
+	return true, nil
+}
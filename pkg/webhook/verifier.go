@@ -0,0 +1,206 @@
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSignatureHeader = "X-Signature"
+	DefaultTimestampHeader = "X-Timestamp"
+	DefaultClockSkew       = 5 * time.Minute
+	// DefaultMaxBodyBytes максимальный размер тела, которое читается для проверки подписи
+	DefaultMaxBodyBytes = 10 << 20
+	// signaturePrefix необязательный префикс подписи в заголовке, например "sha256=3f2a..."
+	signaturePrefix = "sha256="
+)
+
+var (
+	ErrSignatureMissing = errors.New("request signature is missing")
+	ErrSignatureInvalid = errors.New("request signature is invalid")
+	ErrTimestampInvalid = errors.New("request timestamp is invalid")
+	ErrTimestampSkewed  = errors.New("request timestamp is outside of allowed clock skew")
+	ErrReplayed         = errors.New("request has already been received")
+)
+
+// Verifier проверяет HMAC-SHA256 подпись входящих webhook запросов.
+// Подписывается строка "METHOD\npath\ntimestamp\nbody" (с заголовком nonce - "METHOD\npath\ntimestamp\nnonce\nbody"),
+// подпись передается в hex, можно с префиксом "sha256="
+type Verifier struct {
+	// Secrets секреты партнера. Несколько - на время ротации, подпись принимается любым из них
+	Secrets [][]byte
+	// SignatureHeader заголовок с подписью, пустой - DefaultSignatureHeader
+	SignatureHeader string
+	// TimestampHeader заголовок с временем запроса (unix секунды), пустой - DefaultTimestampHeader
+	TimestampHeader string
+	// NonceHeader заголовок с уникальным id запроса. Пустой - в качестве nonce используется сама подпись
+	NonceHeader string
+	// ClockSkew допустимое расхождение времени запроса с текущим, 0 - DefaultClockSkew
+	ClockSkew time.Duration
+	// NonceCache защита от повтора запросов, nil - без защиты (остается только проверка времени)
+	NonceCache NonceCache
+	// MaxBodyBytes максимальный размер тела, 0 - DefaultMaxBodyBytes
+	MaxBodyBytes int64
+}
+
+// NewVerifier Verifier с заголовками по умолчанию и nonce cache в памяти
+func NewVerifier(secrets ...string) *Verifier {
+	verifier := &Verifier{NonceCache: NewMemoryNonceCache()}
+
+	for _, secret := range secrets {
+		verifier.Secrets = append(verifier.Secrets, []byte(secret))
+	}
+
+	return verifier
+}
+
+// Verify проверяет подпись, время и уникальность запроса с уже прочитанным телом body
+func (v *Verifier) Verify(ctx context.Context, request *http.Request, body []byte) error {
+	signature, err := decodeSignature(request.Header.Get(v.getSignatureHeader()))
+
+	if err != nil {
+		return err
+	}
+
+	timestamp := request.Header.Get(v.getTimestampHeader())
+	unixSeconds, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil {
+		return ErrTimestampInvalid
+	}
+
+	if math.Abs(time.Since(time.Unix(unixSeconds, 0)).Seconds()) > v.getClockSkew().Seconds() {
+		return ErrTimestampSkewed
+	}
+
+	nonce := ""
+
+	if v.NonceHeader != "" {
+		if nonce = request.Header.Get(v.NonceHeader); nonce == "" {
+			return ErrSignatureInvalid
+		}
+	}
+
+	if !v.isValidSignature(v.signingPayload(request, timestamp, nonce, body), signature) {
+		return ErrSignatureInvalid
+	}
+
+	if v.NonceCache == nil {
+		return nil
+	}
+
+	if nonce == "" {
+		nonce = hex.EncodeToString(signature)
+	}
+
+	// запросы старше ClockSkew отсекаются проверкой времени, поэтому nonce достаточно хранить 2*ClockSkew
+	added, err := v.NonceCache.Add(ctx, nonce, 2*v.getClockSkew())
+
+	if err != nil {
+		return err
+	}
+
+	if !added {
+		return ErrReplayed
+	}
+
+	return nil
+}
+
+// Sign подпись запроса в hex (для исходящих webhook и тестов)
+func Sign(secret []byte, method string, path string, timestamp string, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(buildPayload(method, path, timestamp, nonce, body))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// GetMaxBodyBytes максимальный размер тела
+func (v *Verifier) GetMaxBodyBytes() int64 {
+	if v.MaxBodyBytes <= 0 {
+		return DefaultMaxBodyBytes
+	}
+
+	return v.MaxBodyBytes
+}
+
+func (v *Verifier) signingPayload(request *http.Request, timestamp string, nonce string, body []byte) []byte {
+	return buildPayload(request.Method, request.URL.EscapedPath(), timestamp, nonce, body)
+}
+
+func (v *Verifier) isValidSignature(payload []byte, signature []byte) bool {
+	valid := false
+
+	for _, secret := range v.Secrets {
+		mac := hmac.New(sha256.New, secret)
+		mac.Write(payload)
+
+		if hmac.Equal(signature, mac.Sum(nil)) {
+			valid = true
+		}
+	}
+
+	return valid
+}
+
+func (v *Verifier) getSignatureHeader() string {
+	if v.SignatureHeader == "" {
+		return DefaultSignatureHeader
+	}
+
+	return v.SignatureHeader
+}
+
+func (v *Verifier) getTimestampHeader() string {
+	if v.TimestampHeader == "" {
+		return DefaultTimestampHeader
+	}
+
+	return v.TimestampHeader
+}
+
+func (v *Verifier) getClockSkew() time.Duration {
+	if v.ClockSkew <= 0 {
+		return DefaultClockSkew
+	}
+
+	return v.ClockSkew
+}
+
+func buildPayload(method string, path string, timestamp string, nonce string, body []byte) []byte {
+	builder := strings.Builder{}
+	builder.Grow(len(method) + len(path) + len(timestamp) + len(nonce) + len(body) + 4)
+	builder.WriteString(strings.ToUpper(method) + "\n" + path + "\n" + timestamp + "\n")
+
+	if nonce != "" {
+		builder.WriteString(nonce + "\n")
+	}
+
+	builder.Write(body)
+
+	return []byte(builder.String())
+}
+
+func decodeSignature(value string) ([]byte, error) {
+	value = strings.TrimPrefix(strings.TrimSpace(value), signaturePrefix)
+
+	if value == "" {
+		return nil, ErrSignatureMissing
+	}
+
+	signature, err := hex.DecodeString(value)
+
+	if err != nil || len(signature) != sha256.Size {
+		return nil, ErrSignatureInvalid
+	}
+
+	return signature, nil
+}
@@ -0,0 +1,205 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testBody = `{"event":"order.paid"}`
+
+// signedRequest запрос, подписанный secret, с временем timestamp и nonce (пустой - без заголовка nonce)
+func signedRequest(secret string, timestamp time.Time, nonce string, body string) *http.Request {
+	request := httptest.NewRequest(http.MethodPost, "/webhooks/partner", strings.NewReader(body))
+	unix := strconv.FormatInt(timestamp.Unix(), 10)
+	request.Header.Set(DefaultTimestampHeader, unix)
+	request.Header.Set(DefaultSignatureHeader, "sha256="+Sign([]byte(secret), http.MethodPost, "/webhooks/partner", unix, nonce, []byte(body)))
+
+	if nonce != "" {
+		request.Header.Set("X-Nonce", nonce)
+	}
+
+	return request
+}
+
+func TestVerifierVerify(t *testing.T) {
+	tests := []struct {
+		name        string
+		nonceHeader string
+		request     func() *http.Request
+		body        string
+		wantErr     error
+	}{
+		{
+			name:    "valid",
+			request: func() *http.Request { return signedRequest("secret", time.Now(), "", testBody) },
+		},
+		{
+			name:    "previous secret during rotation",
+			request: func() *http.Request { return signedRequest("old-secret", time.Now(), "", testBody) },
+		},
+		{
+			name:    "unknown secret",
+			request: func() *http.Request { return signedRequest("other", time.Now(), "", testBody) },
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name:    "tampered body",
+			request: func() *http.Request { return signedRequest("secret", time.Now(), "", testBody) },
+			body:    `{"event":"order.refunded"}`,
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name: "tampered path",
+			request: func() *http.Request {
+				request := signedRequest("secret", time.Now(), "", testBody)
+				request.URL.Path = "/webhooks/other"
+
+				return request
+			},
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name: "tampered timestamp",
+			request: func() *http.Request {
+				request := signedRequest("secret", time.Now(), "", testBody)
+				request.Header.Set(DefaultTimestampHeader, strconv.FormatInt(time.Now().Unix()+1, 10))
+
+				return request
+			},
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name: "missing signature",
+			request: func() *http.Request {
+				request := signedRequest("secret", time.Now(), "", testBody)
+				request.Header.Del(DefaultSignatureHeader)
+
+				return request
+			},
+			wantErr: ErrSignatureMissing,
+		},
+		{
+			name: "signature is not hex",
+			request: func() *http.Request {
+				request := signedRequest("secret", time.Now(), "", testBody)
+				request.Header.Set(DefaultSignatureHeader, "sha256=xyz")
+
+				return request
+			},
+			wantErr: ErrSignatureInvalid,
+		},
+		{
+			name: "invalid timestamp",
+			request: func() *http.Request {
+				request := signedRequest("secret", time.Now(), "", testBody)
+				request.Header.Set(DefaultTimestampHeader, "yesterday")
+
+				return request
+			},
+			wantErr: ErrTimestampInvalid,
+		},
+		{
+			name:    "expired timestamp",
+			request: func() *http.Request { return signedRequest("secret", time.Now().Add(-10*time.Minute), "", testBody) },
+			wantErr: ErrTimestampSkewed,
+		},
+		{
+			name:    "timestamp in the future",
+			request: func() *http.Request { return signedRequest("secret", time.Now().Add(10*time.Minute), "", testBody) },
+			wantErr: ErrTimestampSkewed,
+		},
+		{
+			name:        "with nonce",
+			nonceHeader: "X-Nonce",
+			request:     func() *http.Request { return signedRequest("secret", time.Now(), "evt-1", testBody) },
+		},
+		{
+			name:        "missing nonce",
+			nonceHeader: "X-Nonce",
+			request:     func() *http.Request { return signedRequest("secret", time.Now(), "", testBody) },
+			wantErr:     ErrSignatureInvalid,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier("secret", "old-secret")
+			verifier.NonceHeader = tt.nonceHeader
+			body := tt.body
+
+			if body == "" {
+				body = testBody
+			}
+
+			if err := verifier.Verify(context.Background(), tt.request(), []byte(body)); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Verify() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifierReplay(t *testing.T) {
+	tests := []struct {
+		name        string
+		nonceHeader string
+		nonce       string
+	}{
+		{name: "signature as nonce"},
+		{name: "nonce header", nonceHeader: "X-Nonce", nonce: "evt-1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := NewVerifier("secret")
+			verifier.NonceHeader = tt.nonceHeader
+			timestamp := time.Now()
+
+			if err := verifier.Verify(context.Background(), signedRequest("secret", timestamp, tt.nonce, testBody), []byte(testBody)); err != nil {
+				t.Fatalf("first Verify() error = %v", err)
+			}
+
+			err := verifier.Verify(context.Background(), signedRequest("secret", timestamp, tt.nonce, testBody), []byte(testBody))
+
+			if !errors.Is(err, ErrReplayed) {
+				t.Fatalf("replayed Verify() error = %v, want %v", err, ErrReplayed)
+			}
+		})
+	}
+}
+
+func TestVerifierWithoutNonceCacheAllowsRepeat(t *testing.T) {
+	verifier := NewVerifier("secret")
+	verifier.NonceCache = nil
+	timestamp := time.Now()
+
+	for range 2 {
+		if err := verifier.Verify(context.Background(), signedRequest("secret", timestamp, "", testBody), []byte(testBody)); err != nil {
+			t.Fatalf("Verify() error = %v", err)
+		}
+	}
+}
+
+func TestMemoryNonceCache(t *testing.T) {
+	cache := NewMemoryNonceCache()
+	ctx := context.Background()
+
+	if added, _ := cache.Add(ctx, "a", time.Millisecond); !added {
+		t.Fatal("first Add() = false")
+	}
+
+	if added, _ := cache.Add(ctx, "a", time.Millisecond); added {
+		t.Fatal("repeated Add() = true")
+	}
+
+	time.Sleep(2 * time.Millisecond)
+
+	if added, _ := cache.Add(ctx, "a", time.Millisecond); !added {
+		t.Fatal("Add() after ttl = false")
+	}
+}